@@ -1,21 +1,20 @@
 package blog
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
 	"log/slog"
 	"os"
-	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/babarot/blog/internal/config"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/lipgloss"
-	"gopkg.in/yaml.v2"
 )
 
 const LocalHost = "http://localhost"
@@ -23,7 +22,10 @@ const LocalHost = "http://localhost"
 type Article struct {
 	Meta
 
-	config config.Blog
+	config     config.Blog
+	permalinks Permalinks
+	// rel is the slash-separated path in the content directory
+	rel string
 
 	Date     time.Time
 	Filename string
@@ -34,21 +36,52 @@ type Article struct {
 // Article implements list.Item
 var _ list.Item = (*Article)(nil)
 
+// URL returns the URL of the article on the site. An absolute url in the
+// front matter is returned as it is.
 func (p Article) URL() string {
-	return path.Join(p.config.URL, "post", p.Date.Format("2006/01/02"), p.Slug())
+	if isAbsURL(p.Meta.URL) {
+		return p.Meta.URL
+	}
+	return strings.TrimSuffix(p.config.URL, "/") + p.Permalink()
 }
 
 func (p Article) DevURL() string {
-	localhost := fmt.Sprintf("%s:%d", LocalHost, p.config.DevPort)
-	return path.Join(localhost, "post", p.Date.Format("2006/01/02"), p.Slug())
+	if isAbsURL(p.Meta.URL) {
+		return p.Meta.URL
+	}
+	return fmt.Sprintf("%s:%d", LocalHost, p.config.DevPort) + p.Permalink()
 }
 
+func isAbsURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// WithDevPort returns the article whose DevURL points to the given port,
+// which is the one the hugo server actually listens on.
+func (p Article) WithDevPort(port int) Article {
+	if port > 0 {
+		p.config.DevPort = port
+	}
+	return p
+}
+
+var yearPattern = regexp.MustCompile(`^20\d{2}$`)
+
+// Slug returns the slug of the front matter, or else the name of the
+// directory of the article, or the one of the file in a year directory such
+// as 2024/hello.md. A permalink pattern with :filename or :contentbasename
+// takes the name of the file or the page bundle instead.
 func (p Article) Slug() string {
-	slug := p.Dirname
-	if regexp.MustCompile(`^20\d{2}$`).MatchString(slug) {
-		slug = strings.TrimSuffix(p.Filename, filepath.Ext(p.Filename))
+	if p.Meta.Slug != "" {
+		return p.Meta.Slug
+	}
+	if hasToken(p.pattern(), ":filename", ":contentbasename") {
+		return p.contentBaseName()
 	}
-	return slug
+	if yearPattern.MatchString(p.Dirname) {
+		return strings.TrimSuffix(p.Filename, filepath.Ext(p.Filename))
+	}
+	return p.Dirname
 }
 
 func (p Article) Description() string {
@@ -72,8 +105,65 @@ func (p Article) FilterValue() string {
 	return p.Meta.Title + p.Slug()
 }
 
+// Body returns the contents of the article without its front matter.
+func (p Article) Body() ([]byte, error) {
+	page, err := ReadPage(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	return page.Body, nil
+}
+
+// WordCount counts the words of the text. Each CJK character is
+// counted as a word since those languages have no spaces between words.
+func WordCount(text string) int {
+	var count int
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			count++
+			inWord = false
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+			inWord = false
+		default:
+			if !inWord {
+				count++
+			}
+			inWord = true
+		}
+	}
+	return count
+}
+
+// ToggleDraft flips the draft status in the front matter of the article
+// and returns the new status. The rest of the file is kept as it is.
+func (p Article) ToggleDraft() (bool, error) {
+	page, err := ReadPage(p.Path)
+	if err != nil {
+		return false, err
+	}
+	fm := page.FrontMatter
+	draft := !fm.Draft()
+	if err := fm.SetDraft(draft); err != nil {
+		return false, err
+	}
+	if !draft && p.config.Draft.UpdateDateOnPublish {
+		now := time.Now()
+		if err := fm.SetDate(now); err != nil {
+			return false, err
+		}
+		if err := fm.SetLastmod(now); err != nil {
+			return false, err
+		}
+	}
+	return draft, page.WriteFile(p.Path)
+}
+
 type Meta struct {
 	Title       string   `yaml:"title"`
+	Slug        string   `yaml:"slug"`
+	URL         string   `yaml:"url"`
 	Date        string   `yaml:"date"`
 	Description string   `yaml:"description"`
 	Categories  []string `yaml:"categories"`
@@ -87,23 +177,60 @@ type Meta struct {
 }
 
 type Blog struct {
-	Config   config.Blog
-	Path     string
-	Articles []Article
+	Config     config.Blog
+	Permalinks Permalinks
+	Path       string
+	// ContentRoot is the content directory of hugo holding Path, which
+	// the sections of the articles are taken from
+	ContentRoot string
+	Articles    []Article
+	Index       *Index
+
+	// Lenient skips the articles failing to parse, keeping the errors in
+	// Errors, instead of failing the walk.
+	Lenient bool
+	Errors  []error
 }
 
 func Posts(c config.Config) ([]Article, error) {
-	b := Blog{
-		Config: c.Blog,
-		Path:   filepath.Join(c.Hugo.RootDir, c.Hugo.ContentDir),
+	b, err := Load(c)
+	if err != nil {
+		return []Article{}, err
+	}
+	return b.Articles, nil
+}
+
+// Load walks the content directory and returns the articles sorted by date
+// along with the full-text index of their bodies.
+func Load(c config.Config) (*Blog, error) {
+	return load(c, false)
+}
+
+// LoadLenient is Load that skips the articles failing to parse.
+func LoadLenient(c config.Config) (*Blog, error) {
+	return load(c, true)
+}
+
+func load(c config.Config, lenient bool) (*Blog, error) {
+	permalinks, err := NewPermalinks(c)
+	if err != nil {
+		return nil, err
+	}
+	b := &Blog{
+		Config:      c.Blog,
+		Permalinks:  permalinks,
+		Path:        filepath.Join(c.Hugo.RootDir, c.Hugo.ContentDir),
+		ContentRoot: c.Hugo.ContentRoot(),
+		Index:       NewIndex(),
+		Lenient:     lenient,
 	}
 	if err := b.Walk(); err != nil {
-		return []Article{}, err
+		return nil, err
 	}
 	sort.Slice(b.Articles, func(i, j int) bool {
 		return b.Articles[i].Date.After(b.Articles[j].Date)
 	})
-	return b.Articles, nil
+	return b, nil
 }
 
 func (p *Blog) Walk() error {
@@ -120,62 +247,89 @@ func (p *Blog) Walk() error {
 		default:
 			return nil
 		}
-		content, err := readFrontMatter(path)
+		data, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		var meta Meta
-		if err = yaml.Unmarshal(content, &meta); err != nil {
-			return err
+		format, content, body, err := splitFrontMatter(data)
+		if err != nil {
+			return p.skip(fmt.Errorf("%s: %w", path, err))
 		}
-
-		formats := []string{
-			"2006-01-02T15:04:05-07:00",
-			"2006-01-02T15:04:05",
-			"2006-01-02",
+		fm, err := ParseFrontMatter(format, content)
+		if err != nil {
+			return p.skip(fmt.Errorf("%s: %w", path, err))
 		}
-		var date time.Time
-		for _, format := range formats {
-			date, err = time.Parse(format, meta.Date)
-			if err == nil {
-				break
-			}
+		meta, err := fm.Meta()
+		if err != nil {
+			return p.skip(fmt.Errorf("%s: %w", path, err))
 		}
+
+		date, err := ParseDate(meta.Date)
 		if err != nil {
 			slog.Warn("failed to parse datetime with all formats",
 				"error", err,
 				"input", meta.Date)
 		}
 
+		if p.Index != nil {
+			offset := bytes.Count(data[:len(data)-len(body)], []byte("\n"))
+			p.Index.add(path, body, offset)
+		}
+
+		rel, err := p.rel(path)
+		if err != nil {
+			return err
+		}
 		p.Articles = append(p.Articles, Article{
-			config:   p.Config,
-			Date:     date,
-			Filename: filepath.Base(path),
-			Dirname:  filepath.Base(filepath.Dir(path)),
-			Path:     path,
-			Meta:     meta,
+			config:     p.Config,
+			permalinks: p.Permalinks,
+			rel:        filepath.ToSlash(rel),
+			Date:       date,
+			Filename:   filepath.Base(path),
+			Dirname:    filepath.Base(filepath.Dir(path)),
+			Path:       path,
+			Meta:       meta,
 		})
 		return nil
 	})
 }
 
-func readFrontMatter(path string) ([]byte, error) {
-	var encount int
-	var content string
-	file, err := os.Open(path)
-	if err != nil {
-		return []byte{}, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		if scanner.Text() == "---" {
-			encount++
+// rel returns the path of the article in the content root, or in Path
+// when it is out of the content root.
+func (p *Blog) rel(path string) (string, error) {
+	if p.ContentRoot != "" {
+		rel, err := filepath.Rel(p.ContentRoot, path)
+		if err == nil && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return rel, nil
 		}
-		if encount == 2 {
+	}
+	return filepath.Rel(p.Path, path)
+}
+
+// skip records the error of an article if Lenient, or returns it.
+func (p *Blog) skip(err error) error {
+	if !p.Lenient {
+		return err
+	}
+	p.Errors = append(p.Errors, err)
+	return nil
+}
+
+// ParseDate parses a front matter date in one of the formats Hugo accepts.
+func ParseDate(s string) (time.Time, error) {
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05-07:00",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	}
+	var date time.Time
+	var err error
+	for _, format := range formats {
+		date, err = time.Parse(format, s)
+		if err == nil {
 			break
 		}
-		content += scanner.Text() + "\n"
 	}
-	return []byte(content), scanner.Err()
+	return date, err
 }
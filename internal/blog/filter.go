@@ -0,0 +1,72 @@
+package blog
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Filter narrows down a list of articles.
+// The zero value matches every article.
+type Filter struct {
+	WithTags  bool
+	NoTags    bool
+	NoDraft   bool
+	DraftOnly bool
+
+	// Since and Until bound the article date (inclusive).
+	// A zero time means unbounded.
+	Since time.Time
+	Until time.Time
+
+	// Tags and Categories match an article that has at least one of them
+	// (case-insensitive). When both are given, both must match.
+	Tags       []string
+	Categories []string
+}
+
+func (f Filter) Match(p Article) bool {
+	if f.WithTags && len(p.Meta.Tags) == 0 {
+		return false
+	}
+	if f.NoTags && len(p.Meta.Tags) > 0 {
+		return false
+	}
+	if f.NoDraft && p.Meta.Draft {
+		return false
+	}
+	if f.DraftOnly && !p.Meta.Draft {
+		return false
+	}
+	if !f.Since.IsZero() && p.Date.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && p.Date.After(f.Until) {
+		return false
+	}
+	if len(f.Tags) > 0 && !containsAny(p.Meta.Tags, f.Tags) {
+		return false
+	}
+	if len(f.Categories) > 0 && !containsAny(p.Meta.Categories, f.Categories) {
+		return false
+	}
+	return true
+}
+
+func (f Filter) Apply(articles []Article) []Article {
+	var filtered []Article
+	for _, article := range articles {
+		if f.Match(article) {
+			filtered = append(filtered, article)
+		}
+	}
+	return filtered
+}
+
+func containsAny(have, want []string) bool {
+	return slices.ContainsFunc(have, func(h string) bool {
+		return slices.ContainsFunc(want, func(w string) bool {
+			return strings.EqualFold(h, w)
+		})
+	})
+}
@@ -0,0 +1,368 @@
+package blog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a front matter format supported by Hugo.
+type Format string
+
+const (
+	YAML Format = "yaml"
+	TOML Format = "toml"
+	JSON Format = "json"
+)
+
+const (
+	yamlDelimiter = "---"
+	tomlDelimiter = "+++"
+)
+
+// ArchetypeFormat returns the front matter format of the archetype hugo
+// uses for the kind: archetypes/<kind>.md or archetypes/<kind>/index.md,
+// and then archetypes/default.md. Without any of them, it is TOML as the
+// built-in archetype of hugo.
+func ArchetypeFormat(rootDir, kind string) Format {
+	var names []string
+	if kind != "" {
+		names = append(names, kind+".md", filepath.Join(kind, "index.md"))
+	}
+	names = append(names, "default.md")
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(rootDir, "archetypes", name))
+		if err != nil {
+			continue
+		}
+		if format, _, _, err := splitFrontMatter(data); err == nil && format != "" {
+			return format
+		}
+	}
+	return TOML
+}
+
+// splitFrontMatter detects the front matter format and returns the front
+// matter without its delimiters and everything after it. A file without
+// front matter is returned as the body with an empty format.
+func splitFrontMatter(data []byte) (Format, []byte, []byte, error) {
+	switch {
+	case hasDelimiterLine(data, yamlDelimiter):
+		fm, body, err := splitDelimited(data, yamlDelimiter)
+		return YAML, fm, body, err
+	case hasDelimiterLine(data, tomlDelimiter):
+		fm, body, err := splitDelimited(data, tomlDelimiter)
+		return TOML, fm, body, err
+	case bytes.HasPrefix(data, []byte("{")):
+		dec := json.NewDecoder(bytes.NewReader(data))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return JSON, nil, nil, fmt.Errorf("front matter is not valid JSON: %w", err)
+		}
+		end := int(dec.InputOffset())
+		body := data[end:]
+		// the line break after the closing brace belongs to the front matter
+		body = bytes.TrimPrefix(bytes.TrimPrefix(body, []byte("\r")), []byte("\n"))
+		return JSON, data[:end], body, nil
+	}
+	return "", nil, data, nil
+}
+
+func hasDelimiterLine(data []byte, delimiter string) bool {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	return string(bytes.TrimRight(line, "\r")) == delimiter
+}
+
+func splitDelimited(data []byte, delimiter string) ([]byte, []byte, error) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var fm []byte
+	for i := 1; i < len(lines); i++ {
+		if string(bytes.TrimRight(lines[i], "\r\n")) == delimiter {
+			return fm, bytes.Join(lines[i+1:], nil), nil
+		}
+		fm = append(fm, lines[i]...)
+	}
+	return nil, nil, errors.New("front matter is not closed")
+}
+
+// delimit wraps the encoded front matter with the delimiters of the format.
+func delimit(format Format, fm []byte) []byte {
+	var buf bytes.Buffer
+	switch format {
+	case TOML:
+		buf.WriteString(tomlDelimiter + "\n")
+		buf.Write(fm)
+		buf.WriteString(tomlDelimiter + "\n")
+	case JSON:
+		buf.Write(fm)
+		buf.WriteString("\n")
+	default:
+		buf.WriteString(yamlDelimiter + "\n")
+		buf.Write(fm)
+		buf.WriteString(yamlDelimiter + "\n")
+	}
+	return buf.Bytes()
+}
+
+// parseTOML converts a TOML document into a YAML mapping node
+// keeping the order of the top-level keys.
+func parseTOML(data []byte) (*yaml.Node, error) {
+	var m map[string]any
+	md, err := toml.Decode(string(data), &m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse front matter: %w", err)
+	}
+	doc := &yaml.Node{
+		Kind:    yaml.DocumentNode,
+		Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+	}
+	mapping := doc.Content[0]
+	for _, key := range md.Keys() {
+		if len(key) != 1 {
+			continue
+		}
+		var v yaml.Node
+		if err := v.Encode(m[key[0]]); err != nil {
+			return nil, err
+		}
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key[0]},
+			&v,
+		)
+	}
+	return doc, nil
+}
+
+var (
+	tomlTableRe = regexp.MustCompile(`^\s*\[\[?\s*("[^"]*"|'[^']*'|[A-Za-z0-9_-]+)`)
+	tomlKeyRe   = regexp.MustCompile(`^\s*("[^"]*"|'[^']*'|[A-Za-z0-9_-]+)\s*[.=]`)
+)
+
+// tomlBytes rewrites only the changed top-level keys and tables
+// of the original TOML document.
+func (f *FrontMatter) tomlBytes() ([]byte, error) {
+	lines := strings.SplitAfter(string(f.raw), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	type span struct {
+		key        string
+		table      bool
+		start, end int
+	}
+	var spans []span
+	inTable := false
+	// the delimiter of the multi-line string the line is in
+	inString := ""
+	for i, line := range lines {
+		if inString != "" {
+			if strings.Count(line, inString)%2 == 1 {
+				inString = ""
+			}
+			continue
+		}
+		for _, delim := range []string{`"""`, "'''"} {
+			if strings.Count(line, delim)%2 == 1 {
+				inString = delim
+				break
+			}
+		}
+		if m := tomlTableRe.FindStringSubmatch(line); m != nil {
+			inTable = true
+			spans = append(spans, span{key: strings.Trim(m[1], `"'`), table: true, start: i})
+			continue
+		}
+		if inTable {
+			continue
+		}
+		if m := tomlKeyRe.FindStringSubmatch(line); m != nil {
+			spans = append(spans, span{key: strings.Trim(m[1], `"'`), start: i})
+		}
+	}
+	for i := range spans {
+		end := len(lines)
+		if i+1 < len(spans) {
+			end = spans[i+1].start
+		}
+		for end > spans[i].start+1 && isBlankOrComment(lines[end-1]) {
+			end--
+		}
+		spans[i].end = end
+	}
+	// new plain keys go after the last plain key, or before the first table
+	insertAt := len(lines)
+	if i := slices.IndexFunc(spans, func(s span) bool { return s.table }); i >= 0 {
+		insertAt = spans[i].start
+	}
+	for _, s := range spans {
+		if !s.table {
+			insertAt = s.end
+		}
+	}
+	existed := func(key string) bool {
+		return slices.ContainsFunc(spans, func(s span) bool { return s.key == key })
+	}
+
+	var buf, tables bytes.Buffer
+	pos := 0
+	written := map[string]bool{}
+	flush := func(to int) error {
+		if pos <= insertAt && insertAt <= to {
+			buf.WriteString(strings.Join(lines[pos:insertAt], ""))
+			pos = insertAt
+			insertAt = -1
+			if err := f.appendTOMLKeys(&buf, &tables, existed, false); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(strings.Join(lines[pos:to], ""))
+		return nil
+	}
+	for _, s := range spans {
+		if err := flush(s.start); err != nil {
+			return nil, err
+		}
+		pos = s.end
+		if !f.dirty[s.key] {
+			buf.WriteString(strings.Join(lines[s.start:s.end], ""))
+			continue
+		}
+		if written[s.key] || !f.Has(s.key) {
+			continue
+		}
+		written[s.key] = true
+		out, err := f.encodeTOMLKey(s.key)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(out, []byte("[")) {
+			tables.Write(out)
+		} else {
+			buf.Write(out)
+		}
+	}
+	if err := flush(len(lines)); err != nil {
+		return nil, err
+	}
+	if tables.Len() > 0 {
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n\n")) {
+			buf.WriteString("\n")
+		}
+		buf.Write(tables.Bytes())
+	}
+	// the lines are split without parsing the values, so make sure nothing
+	// has been broken, or give up on keeping the original lines
+	if !f.sameTOML(buf.Bytes()) {
+		return f.encodeTOMLAll()
+	}
+	return buf.Bytes(), nil
+}
+
+// sameTOML reports whether the TOML document holds the values of f.
+func (f *FrontMatter) sameTOML(data []byte) bool {
+	doc, err := parseTOML(data)
+	if err != nil {
+		return false
+	}
+	var got, want any
+	if doc.Content[0].Decode(&got) != nil || f.mapping().Decode(&want) != nil {
+		return false
+	}
+	a, err := json.Marshal(got)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(want)
+	return err == nil && bytes.Equal(a, b)
+}
+
+// encodeTOMLAll encodes the whole document, the tables after the plain keys.
+func (f *FrontMatter) encodeTOMLAll() ([]byte, error) {
+	var buf, tables bytes.Buffer
+	if err := f.appendTOMLKeys(&buf, &tables, func(string) bool { return false }, true); err != nil {
+		return nil, err
+	}
+	if tables.Len() > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.Write(tables.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// appendTOMLKeys writes the keys that did not exist originally, or all the
+// keys if all is true. Tables are written to tables so that they can be
+// placed after every plain key.
+func (f *FrontMatter) appendTOMLKeys(buf, tables *bytes.Buffer, existed func(string) bool, all bool) error {
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	for _, key := range f.Keys() {
+		if !all && (!f.dirty[key] || existed(key)) {
+			continue
+		}
+		out, err := f.encodeTOMLKey(key)
+		if err != nil {
+			return err
+		}
+		if bytes.HasPrefix(out, []byte("[")) {
+			tables.Write(out)
+		} else {
+			buf.Write(out)
+		}
+	}
+	return nil
+}
+
+func (f *FrontMatter) encodeTOMLKey(key string) ([]byte, error) {
+	var v any
+	if _, err := f.Get(key, &v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(map[string]any{key: v}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonBytes encodes the whole document keeping the order of the keys
+// as JSON has no comments to preserve.
+func (f *FrontMatter) jsonBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	m := f.mapping()
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		var v any
+		if err := m.Content[i+1].Decode(&v); err != nil {
+			return nil, err
+		}
+		key, err := json.Marshal(m.Content[i].Value)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.MarshalIndent(v, "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, "\n  %s: %s", key, value)
+	}
+	buf.WriteString("\n}")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,145 @@
+package blog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTOMLRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		set  func(*FrontMatter) error
+		// want is the output when the original lines can be kept
+		want string
+	}{
+		{
+			name: "draft",
+			data: "# comment\ntitle = \"Hello\"\ndraft = true # inline\ntags = [\"go\"]\n",
+			set:  func(fm *FrontMatter) error { return fm.SetDraft(false) },
+			want: "# comment\ntitle = \"Hello\"\ndraft = false\ntags = [\"go\"]\n",
+		},
+		{
+			name: "new key before table",
+			data: "title = \"Hello\"\n\n[params]\n  image = \"a.png\"\n",
+			set:  func(fm *FrontMatter) error { return fm.SetTags([]string{"go"}) },
+			want: "title = \"Hello\"\ntags = [\"go\"]\n\n[params]\n  image = \"a.png\"\n",
+		},
+		{
+			name: "key in multi-line string",
+			data: "title = \"Hello\"\ndescription = \"\"\"\nline\ntags = [\"fake\"]\n\"\"\"\ntags = [\"go\"]\n",
+			set:  func(fm *FrontMatter) error { return fm.SetTags([]string{"cli"}) },
+			want: "title = \"Hello\"\ndescription = \"\"\"\nline\ntags = [\"fake\"]\n\"\"\"\ntags = [\"cli\"]\n",
+		},
+		{
+			name: "key in multi-line literal string",
+			data: "description = '''\ndraft = true\n'''\ndraft = true\n",
+			set:  func(fm *FrontMatter) error { return fm.SetDraft(false) },
+			want: "description = '''\ndraft = true\n'''\ndraft = false\n",
+		},
+		{
+			name: "multi-line array",
+			data: "tags = [\n  \"go\",\n  \"cli\",\n]\ntitle = \"Hello\"\n",
+			set:  func(fm *FrontMatter) error { return fm.SetTitle("World") },
+			want: "tags = [\n  \"go\",\n  \"cli\",\n]\ntitle = \"World\"\n",
+		},
+		{
+			name: "string that cannot be split by lines",
+			data: "description = \"\"\" one \"\"\" \ntitle = \"\"\"\na\"\"\" \ndraft = true\n",
+			set:  func(fm *FrontMatter) error { return fm.SetDraft(false) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, err := ParseFrontMatter(TOML, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.set(fm); err != nil {
+				t.Fatal(err)
+			}
+			out, err := fm.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != "" && string(out) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", out, tt.want)
+			}
+
+			// the output has the values set and keeps the others
+			got, err := ParseFrontMatter(TOML, out)
+			if err != nil {
+				t.Fatalf("output does not parse: %v\n%s", err, out)
+			}
+			var want, have map[string]any
+			if err := fm.mapping().Decode(&want); err != nil {
+				t.Fatal(err)
+			}
+			if err := got.mapping().Decode(&have); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("values = %v, want %v\n%s", have, want, out)
+			}
+		})
+	}
+}
+
+func TestArchetypeFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		kind  string
+		want  Format
+	}{
+		{
+			name:  "hugo default",
+			files: map[string]string{"hugo.yaml": "title: x\n"},
+			want:  TOML,
+		},
+		{
+			name:  "default archetype",
+			files: map[string]string{"hugo.toml": "", "archetypes/default.md": "---\ntitle: x\n---\n"},
+			want:  YAML,
+		},
+		{
+			name: "archetype of the kind",
+			files: map[string]string{
+				"archetypes/default.md":    "---\ntitle: x\n---\n",
+				"archetypes/note/index.md": "{\n  \"title\": \"x\"\n}\n",
+			},
+			kind: "note",
+			want: JSON,
+		},
+		{
+			name:  "archetype without front matter",
+			files: map[string]string{"archetypes/post.md": "body\n", "archetypes/default.md": "---\n---\n"},
+			kind:  "post",
+			want:  YAML,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, root, name, content)
+			}
+			if got := ArchetypeFormat(root, tt.kind); got != tt.want {
+				t.Errorf("ArchetypeFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,357 @@
+package blog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter is a front matter document that keeps the original key order,
+// comments and the keys not modelled by Meta. When written back, only the
+// lines of the keys changed through Set or Delete are rewritten.
+type FrontMatter struct {
+	format Format
+	raw    []byte
+	doc    *yaml.Node
+	dirty  map[string]bool
+}
+
+// NewFrontMatter returns an empty document written in the format.
+func NewFrontMatter(format Format) *FrontMatter {
+	return &FrontMatter{
+		format: format,
+		doc: &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		},
+		dirty: map[string]bool{},
+	}
+}
+
+// ParseFrontMatter parses the front matter without its delimiters.
+func ParseFrontMatter(format Format, data []byte) (*FrontMatter, error) {
+	var doc yaml.Node
+	switch format {
+	case TOML:
+		d, err := parseTOML(data)
+		if err != nil {
+			return nil, err
+		}
+		doc = *d
+	default:
+		// JSON is a subset of YAML
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse front matter: %w", err)
+		}
+	}
+	if doc.Kind == 0 {
+		// empty front matter
+		f := NewFrontMatter(format)
+		f.raw = data
+		return f, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("front matter is not a mapping")
+	}
+	return &FrontMatter{
+		format: format,
+		raw:    data,
+		doc:    &doc,
+		dirty:  map[string]bool{},
+	}, nil
+}
+
+func (f *FrontMatter) Format() Format {
+	return f.format
+}
+
+func (f *FrontMatter) mapping() *yaml.Node {
+	return f.doc.Content[0]
+}
+
+// index returns the position of the key node in the mapping, or -1.
+func (f *FrontMatter) index(key string) int {
+	m := f.mapping()
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Keys returns the top-level keys in the order they appear.
+func (f *FrontMatter) Keys() []string {
+	var keys []string
+	m := f.mapping()
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		keys = append(keys, m.Content[i].Value)
+	}
+	return keys
+}
+
+func (f *FrontMatter) Has(key string) bool {
+	return f.index(key) >= 0
+}
+
+// Get decodes the value of the key into v.
+// It reports whether the key exists.
+func (f *FrontMatter) Get(key string, v any) (bool, error) {
+	i := f.index(key)
+	if i < 0 {
+		return false, nil
+	}
+	return true, f.mapping().Content[i+1].Decode(v)
+}
+
+// Set sets the key to value, replacing the existing value in place
+// or appending the key at the end.
+func (f *FrontMatter) Set(key string, value any) error {
+	var v yaml.Node
+	if n, ok := value.(*yaml.Node); ok {
+		v = *n
+	} else if err := v.Encode(value); err != nil {
+		return err
+	}
+	m := f.mapping()
+	f.dirty[key] = true
+	if i := f.index(key); i >= 0 {
+		old := m.Content[i+1]
+		switch {
+		case old.Tag == "!!timestamp" && v.Tag == "!!str":
+			// keep dates unquoted as they were
+			v.Tag = old.Tag
+			v.Style = 0
+		case old.Kind == v.Kind && old.Tag == v.Tag:
+			// keep the quoting or flow style
+			v.Style = old.Style
+		}
+		// keep the comments attached to the old value
+		v.HeadComment = old.HeadComment
+		v.LineComment = old.LineComment
+		v.FootComment = old.FootComment
+		m.Content[i+1] = &v
+		return nil
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&v,
+	)
+	return nil
+}
+
+func (f *FrontMatter) Delete(key string) {
+	i := f.index(key)
+	if i < 0 {
+		return
+	}
+	m := f.mapping()
+	m.Content = slices.Delete(m.Content, i, i+2)
+	f.dirty[key] = true
+}
+
+// Meta returns the typed view of the known keys.
+func (f *FrontMatter) Meta() (Meta, error) {
+	var meta Meta
+	err := f.mapping().Decode(&meta)
+	return meta, err
+}
+
+func (f *FrontMatter) Title() string {
+	var title string
+	f.Get("title", &title)
+	return title
+}
+
+func (f *FrontMatter) SetTitle(title string) error {
+	return f.Set("title", title)
+}
+
+func (f *FrontMatter) Date() (time.Time, error) {
+	var date string
+	if _, err := f.Get("date", &date); err != nil {
+		return time.Time{}, err
+	}
+	return ParseDate(date)
+}
+
+func (f *FrontMatter) SetDate(date time.Time) error {
+	return f.Set("date", timestampNode(date))
+}
+
+func (f *FrontMatter) SetLastmod(date time.Time) error {
+	return f.Set("lastmod", timestampNode(date))
+}
+
+func timestampNode(t time.Time) *yaml.Node {
+	return &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   "!!timestamp",
+		Value: t.Format("2006-01-02T15:04:05-07:00"),
+	}
+}
+
+func (f *FrontMatter) Draft() bool {
+	var draft bool
+	f.Get("draft", &draft)
+	return draft
+}
+
+func (f *FrontMatter) SetDraft(draft bool) error {
+	return f.Set("draft", draft)
+}
+
+func (f *FrontMatter) Tags() []string {
+	var tags []string
+	f.Get("tags", &tags)
+	return tags
+}
+
+func (f *FrontMatter) SetTags(tags []string) error {
+	return f.Set("tags", tags)
+}
+
+func (f *FrontMatter) Categories() []string {
+	var categories []string
+	f.Get("categories", &categories)
+	return categories
+}
+
+func (f *FrontMatter) SetCategories(categories []string) error {
+	return f.Set("categories", categories)
+}
+
+// Bytes returns the document. The original bytes are returned untouched
+// except for the lines of the keys that have been changed.
+func (f *FrontMatter) Bytes() ([]byte, error) {
+	if len(f.dirty) == 0 {
+		return f.raw, nil
+	}
+	switch f.format {
+	case JSON:
+		return f.jsonBytes()
+	case TOML:
+		if len(f.raw) == 0 {
+			return f.encodeTOMLAll()
+		}
+		return f.tomlBytes()
+	}
+	if f.mapping().Style&yaml.FlowStyle != 0 || len(f.raw) == 0 {
+		return f.encodeAll()
+	}
+
+	lines := strings.SplitAfter(string(f.raw), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// locate every top-level key of the original document
+	var orig yaml.Node
+	if err := yaml.Unmarshal(f.raw, &orig); err != nil {
+		return nil, err
+	}
+	type span struct {
+		key        string
+		start, end int // 0-based line range [start, end)
+	}
+	var spans []span
+	// a document of comments only has no mapping, and every key is new
+	if len(orig.Content) > 0 && orig.Content[0].Kind == yaml.MappingNode {
+		om := orig.Content[0]
+		for i := 0; i+1 < len(om.Content); i += 2 {
+			spans = append(spans, span{key: om.Content[i].Value, start: om.Content[i].Line - 1})
+		}
+	}
+	for i := range spans {
+		end := len(lines)
+		if i+1 < len(spans) {
+			end = spans[i+1].start
+		}
+		// leave the blank lines and top-level comments that follow the value
+		for end > spans[i].start+1 && isBlankOrComment(lines[end-1]) {
+			end--
+		}
+		spans[i].end = end
+	}
+
+	var buf bytes.Buffer
+	pos := 0
+	for _, s := range spans {
+		buf.WriteString(strings.Join(lines[pos:s.start], ""))
+		pos = s.end
+		if !f.dirty[s.key] {
+			buf.WriteString(strings.Join(lines[s.start:s.end], ""))
+			continue
+		}
+		if f.Has(s.key) {
+			out, err := f.encodeKey(s.key)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(out)
+		}
+	}
+	buf.WriteString(strings.Join(lines[pos:], ""))
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+
+	// append the keys that did not exist originally
+	for _, key := range f.Keys() {
+		if !f.dirty[key] || slices.ContainsFunc(spans, func(s span) bool { return s.key == key }) {
+			continue
+		}
+		out, err := f.encodeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(out)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeKey encodes a single "key: value" entry.
+func (f *FrontMatter) encodeKey(key string) ([]byte, error) {
+	i := f.index(key)
+	m := f.mapping()
+	// the comments around the entry are kept in the original lines
+	k, v := *m.Content[i], *m.Content[i+1]
+	k.HeadComment, k.FootComment = "", ""
+	v.HeadComment, v.FootComment = "", ""
+	entry := &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Tag:     "!!map",
+		Content: []*yaml.Node{&k, &v},
+	}
+	return encodeYAML(entry)
+}
+
+func (f *FrontMatter) encodeAll() ([]byte, error) {
+	if len(f.mapping().Content) == 0 {
+		return []byte{}, nil
+	}
+	return encodeYAML(f.doc)
+}
+
+func encodeYAML(n *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(line, "#")
+}
@@ -0,0 +1,164 @@
+package blog
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFrontMatterCommentOnly(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+	}{
+		{name: "yaml", format: YAML, data: "# only a comment\n"},
+		{name: "toml", format: TOML, data: "# only a comment\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, err := ParseFrontMatter(tt.format, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := fm.SetTags([]string{"go"}); err != nil {
+				t.Fatal(err)
+			}
+			out, err := fm.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(out), tt.data) {
+				t.Errorf("comment is not kept:\n%s", out)
+			}
+			got, err := ParseFrontMatter(tt.format, out)
+			if err != nil {
+				t.Fatalf("output does not parse: %v\n%s", err, out)
+			}
+			if tags := got.Tags(); !slices.Equal(tags, []string{"go"}) {
+				t.Errorf("tags = %v, want [go]\n%s", tags, out)
+			}
+		})
+	}
+}
+
+// yamlPage is a page with the comments, the key order and the keys unknown
+// to Meta which the rewrites keep.
+const yamlPage = `---
+# the title is set by hand
+title: "Hello, World"
+date: 2024-03-05T10:00:00+09:00
+tags: [go, hugo]   # flow style
+params:
+  cover: cover.png
+  unknown_key: 1
+
+categories:
+  - tech
+draft: true
+---
+Body with --- inside.
+`
+
+func TestYAMLRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(fm *FrontMatter) error
+		want   string
+	}{
+		{
+			name:   "unchanged",
+			change: func(fm *FrontMatter) error { return nil },
+			want:   yamlPage,
+		},
+		{
+			name:   "only the changed key",
+			change: func(fm *FrontMatter) error { return fm.SetTags([]string{"go", "blog"}) },
+			want:   strings.Replace(yamlPage, "tags: [go, hugo]   # flow style\n", "tags: [go, blog] # flow style\n", 1),
+		},
+		{
+			name:   "a key in the block style",
+			change: func(fm *FrontMatter) error { return fm.SetCategories([]string{"life"}) },
+			want:   strings.Replace(yamlPage, "categories:\n  - tech\n", "categories:\n  - life\n", 1),
+		},
+		{
+			name:   "deleted key",
+			change: func(fm *FrontMatter) error { fm.Delete("draft"); return nil },
+			want:   strings.Replace(yamlPage, "draft: true\n", "", 1),
+		},
+		{
+			name:   "new key at the end",
+			change: func(fm *FrontMatter) error { return fm.Set("slug", "hello") },
+			want:   strings.Replace(yamlPage, "draft: true\n", "draft: true\nslug: hello\n", 1),
+		},
+		{
+			name: "unchanged value",
+			change: func(fm *FrontMatter) error {
+				return fm.SetTitle("Hello, World")
+			},
+			want: yamlPage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := ParsePage([]byte(yamlPage))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.change(page.FrontMatter); err != nil {
+				t.Fatal(err)
+			}
+			got, err := page.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Bytes() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if keys := page.FrontMatter.Keys(); keys[0] != "title" || keys[1] != "date" {
+				t.Errorf("Keys() = %v, want the original order", keys)
+			}
+		})
+	}
+}
+
+func TestCRLF(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "yaml", data: "---\r\ntitle: Hello\r\n# comment\r\ntags:\r\n  - go\r\n---\r\nBody\r\n"},
+		{name: "toml", data: "+++\r\ntitle = \"Hello\"\r\n# comment\r\ntags = [\"go\"]\r\n+++\r\nBody\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := ParsePage([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := page.FrontMatter.SetTags([]string{"go", "hugo"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := page.FrontMatter.Set("slug", "hello"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := page.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lf := strings.Count(string(got), "\n"); lf != strings.Count(string(got), "\r\n") {
+				t.Errorf("line breaks are mixed:\n%q", got)
+			}
+			if !strings.Contains(string(got), "# comment\r\n") || !strings.HasSuffix(string(got), "\r\nBody\r\n") {
+				t.Errorf("the other lines are changed:\n%q", got)
+			}
+			again, err := ParsePage(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tags := again.FrontMatter.Tags(); !slices.Equal(tags, []string{"go", "hugo"}) {
+				t.Errorf("tags = %v, want [go hugo]", tags)
+			}
+		})
+	}
+}
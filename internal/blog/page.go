@@ -0,0 +1,67 @@
+package blog
+
+import (
+	"bytes"
+	"os"
+)
+
+// Page is a Hugo content file split into its front matter and body.
+type Page struct {
+	FrontMatter *FrontMatter
+	Body        []byte
+
+	// crlf keeps the line breaks of a file written with CRLF
+	crlf bool
+}
+
+func ReadPage(path string) (*Page, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePage(data)
+}
+
+func ParsePage(data []byte) (*Page, error) {
+	format, fm, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, err
+	}
+	frontMatter, err := ParseFrontMatter(format, fm)
+	if err != nil {
+		return nil, err
+	}
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	crlf := bytes.HasSuffix(line, []byte("\r"))
+	return &Page{FrontMatter: frontMatter, Body: body, crlf: crlf}, nil
+}
+
+func (p *Page) Bytes() ([]byte, error) {
+	fm, err := p.FrontMatter.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if p.FrontMatter.Format() == "" && len(p.FrontMatter.Keys()) == 0 {
+		// no front matter
+		return p.Body, nil
+	}
+	fm = delimit(p.FrontMatter.Format(), fm)
+	if p.crlf {
+		// the lines rewritten are encoded with LF
+		fm = bytes.ReplaceAll(bytes.ReplaceAll(fm, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+	}
+	return append(fm, p.Body...), nil
+}
+
+// WriteFile writes the page back to the path.
+func (p *Page) WriteFile(path string) error {
+	data, err := p.Bytes()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, info.Mode())
+}
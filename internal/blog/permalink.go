@@ -0,0 +1,170 @@
+package blog
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/babarot/blog/internal/config"
+)
+
+// Permalinks maps the sections to the permalink patterns of their
+// articles. The pattern of the empty section is for the other sections,
+// which take the path of the content as hugo does without one.
+type Permalinks map[string]string
+
+// NewPermalinks returns the patterns of blog.permalink, or the ones of the
+// hugo config when it is "hugo".
+func NewPermalinks(c config.Config) (Permalinks, error) {
+	switch c.Blog.Permalink {
+	case "":
+		return Permalinks{"": config.DefaultPermalink}, nil
+	case config.HugoPermalink:
+	default:
+		return Permalinks{"": c.Blog.Permalink}, nil
+	}
+	site, err := config.ReadHugoSite(c.Hugo.RootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the permalinks of the hugo config: %w", err)
+	}
+	permalinks := Permalinks{}
+	for key, value := range site.Permalinks {
+		switch value := value.(type) {
+		case string:
+			permalinks[key] = value
+		case map[string]any:
+			// permalinks.page.<section>
+			if key == "page" {
+				for section, pattern := range value {
+					if pattern, ok := pattern.(string); ok {
+						permalinks[section] = pattern
+					}
+				}
+			}
+		case map[any]any:
+			if key == "page" {
+				for section, pattern := range value {
+					section, ok1 := section.(string)
+					pattern, ok2 := pattern.(string)
+					if ok1 && ok2 {
+						permalinks[section] = pattern
+					}
+				}
+			}
+		}
+	}
+	return permalinks, nil
+}
+
+var tokenPattern = regexp.MustCompile(`:[a-z]+`)
+
+// Permalink returns the path of the article on the site. The url of the
+// front matter wins over the patterns.
+func (p Article) Permalink() string {
+	if p.Meta.URL != "" {
+		return "/" + strings.TrimPrefix(p.Meta.URL, "/")
+	}
+	pattern := p.pattern()
+	if pattern == "" {
+		return p.contentPath()
+	}
+	return tokenPattern.ReplaceAllStringFunc(pattern, func(token string) string {
+		switch token {
+		case ":year":
+			return p.Date.Format("2006")
+		case ":month":
+			return p.Date.Format("01")
+		case ":monthname":
+			return strings.ToLower(p.Date.Format("January"))
+		case ":day":
+			return p.Date.Format("02")
+		case ":yearday":
+			return strconv.Itoa(p.Date.YearDay())
+		case ":section":
+			return p.Section()
+		case ":title":
+			return Urlize(p.Meta.Title)
+		case ":slug":
+			if p.Meta.Slug != "" {
+				return p.Meta.Slug
+			}
+			return Urlize(p.Meta.Title)
+		case ":filename", ":contentbasename":
+			return p.contentBaseName()
+		case ":slugorfilename", ":slugorcontentbasename":
+			return p.Slug()
+		}
+		return token
+	})
+}
+
+// pattern returns the permalink pattern of the section of the article, or
+// "" if there is none.
+func (p Article) pattern() string {
+	if pattern, ok := p.permalinks[p.Section()]; ok {
+		return pattern
+	}
+	return p.permalinks[""]
+}
+
+// hasToken reports whether the pattern has any of the tokens.
+func hasToken(pattern string, tokens ...string) bool {
+	for _, token := range tokenPattern.FindAllString(pattern, -1) {
+		if slices.Contains(tokens, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// Section returns the top-level directory of the article in the content.
+func (p Article) Section() string {
+	section, _, ok := strings.Cut(p.rel, "/")
+	if !ok {
+		return ""
+	}
+	return section
+}
+
+// contentBaseName returns the name of the file without the extension, or
+// the name of the directory for a page bundle.
+func (p Article) contentBaseName() string {
+	name := strings.TrimSuffix(p.Filename, path.Ext(p.Filename))
+	if name == "index" || name == "_index" {
+		return p.Dirname
+	}
+	return name
+}
+
+// contentPath returns the path hugo gives to the article without any
+// permalink pattern, where the slug replaces the last element.
+func (p Article) contentPath() string {
+	dir, file := path.Split(p.rel)
+	name := strings.TrimSuffix(file, path.Ext(file))
+	if name == "index" || name == "_index" {
+		dir, name = path.Split(strings.TrimSuffix(dir, "/"))
+	}
+	if p.Meta.Slug != "" {
+		name = p.Meta.Slug
+	}
+	return strings.ToLower(path.Join("/", dir, name)) + "/"
+}
+
+// Urlize turns the title into a path element as hugo does, keeping the
+// letters and digits of any language.
+func Urlize(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.Is(unicode.Mn, r), r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
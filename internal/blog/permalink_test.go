@@ -0,0 +1,222 @@
+package blog
+
+import (
+	"testing"
+
+	"github.com/babarot/blog/internal/config"
+)
+
+// testSite is the hugo site the articles of the tests are loaded from.
+type testSite struct {
+	// hugo is the content of hugo.toml
+	hugo       string
+	contentDir string
+	permalink  string
+}
+
+// article writes the file with the front matter in the site and returns
+// the article as Load finds it.
+func (s testSite) article(t *testing.T, file, frontMatter string) Article {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, root, "hugo.toml", s.hugo)
+	writeFile(t, root, file, "---\ndate: \"2024-03-05T10:00:00Z\"\n"+frontMatter+"---\n")
+	contentDir := s.contentDir
+	if contentDir == "" {
+		contentDir = "content"
+	}
+	b, err := Load(config.Config{
+		Blog: config.Blog{Permalink: s.permalink},
+		Hugo: config.Hugo{RootDir: root, ContentDir: contentDir},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Articles) != 1 {
+		t.Fatalf("found %d articles, want 1", len(b.Articles))
+	}
+	return b.Articles[0]
+}
+
+func TestPermalink(t *testing.T) {
+	tests := []struct {
+		name  string
+		site  testSite
+		file  string
+		front string
+		want  string
+	}{
+		{
+			name: "date tokens",
+			site: testSite{contentDir: "content/post", permalink: "/:year/:month/:monthname/:day/:yearday/"},
+			file: "content/post/2024/hello/index.md",
+			want: "/2024/03/march/05/65/",
+		},
+		{
+			name:  "section and title",
+			site:  testSite{contentDir: "content/post", permalink: "/:section/:title/"},
+			file:  "content/post/hello.md",
+			front: "title: Hello, World\n",
+			want:  "/post/hello-world/",
+		},
+		{
+			name:  "slug falls back on the title",
+			site:  testSite{permalink: "/:slug/"},
+			file:  "content/post/hello.md",
+			front: "title: Hello World\n",
+			want:  "/hello-world/",
+		},
+		{
+			name:  "slug of the front matter",
+			site:  testSite{permalink: "/:slug/"},
+			file:  "content/post/hello.md",
+			front: "title: Hello World\nslug: hi\n",
+			want:  "/hi/",
+		},
+		{
+			name: "filename of a page bundle",
+			site: testSite{contentDir: "content/post", permalink: "/:filename/"},
+			file: "content/post/2024/hello/index.md",
+			want: "/hello/",
+		},
+		{
+			name: "contentbasename of a file",
+			site: testSite{contentDir: "content/post", permalink: "/:contentbasename/"},
+			file: "content/post/notes/hello.md",
+			want: "/hello/",
+		},
+		{
+			name: "slugorcontentbasename",
+			site: testSite{contentDir: "content/post"},
+			file: "content/post/2024/hello.md",
+			want: "/post/2024/03/05/hello/",
+		},
+		{
+			name:  "pattern of the section of content_dir",
+			site:  testSite{hugo: "[permalinks]\n  post = \"/p/:year/:slug/\"\n", contentDir: "content/post", permalink: "hugo"},
+			file:  "content/post/2024/world/index.md",
+			front: "title: World\n",
+			want:  "/p/2024/world/",
+		},
+		{
+			name: "pattern of the section of pages",
+			site: testSite{hugo: "[permalinks.page]\n  notes = \"/n/:slugorfilename/\"\n", permalink: "hugo"},
+			file: "content/notes/hello/index.md",
+			want: "/n/hello/",
+		},
+		{
+			name:  "url of the front matter",
+			site:  testSite{permalink: "/:slug/"},
+			file:  "content/post/hello.md",
+			front: "url: about/\n",
+			want:  "/about/",
+		},
+		{
+			name: "content path without a pattern",
+			site: testSite{contentDir: "content/post", permalink: "hugo"},
+			file: "content/post/Hello/index.md",
+			want: "/post/hello/",
+		},
+		{
+			name:  "content path with the slug",
+			site:  testSite{contentDir: "content/post", permalink: "hugo"},
+			file:  "content/post/2024/hello.md",
+			front: "slug: hi\n",
+			want:  "/post/2024/hi/",
+		},
+		{
+			name:  "contentDir of hugo",
+			site:  testSite{hugo: "contentDir = \"src\"\n", contentDir: "src/post", permalink: "/:section/:slug/"},
+			file:  "src/post/hello.md",
+			front: "slug: hi\n",
+			want:  "/post/hi/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.site.article(t, tt.file, tt.front)
+			if got := p.Permalink(); got != tt.want {
+				t.Errorf("Permalink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSection(t *testing.T) {
+	tests := []struct {
+		file       string
+		contentDir string
+		want       string
+	}{
+		{file: "content/post/2024/hello/index.md", contentDir: "content/post", want: "post"},
+		{file: "content/post/2024/hello/index.md", want: "post"},
+		{file: "content/notes/hello.md", want: "notes"},
+		{file: "content/about.md", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			p := testSite{contentDir: tt.contentDir}.article(t, tt.file, "")
+			if got := p.Section(); got != tt.want {
+				t.Errorf("Section() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		site  testSite
+		file  string
+		front string
+		want  string
+	}{
+		{
+			name: "page bundle",
+			file: "content/post/2024/hello/index.md",
+			want: "hello",
+		},
+		{
+			name: "file in a year directory",
+			file: "content/post/2024/hello.md",
+			want: "hello",
+		},
+		{
+			name: "file in another directory",
+			file: "content/post/hello/world.md",
+			want: "hello",
+		},
+		{
+			name:  "front matter",
+			file:  "content/post/hello/world.md",
+			front: "slug: hi\n",
+			want:  "hi",
+		},
+		{
+			name: "pattern with the file name",
+			site: testSite{permalink: "/:contentbasename/"},
+			file: "content/post/hello/world.md",
+			want: "world",
+		},
+		{
+			name: "pattern of the section of content_dir with the file name",
+			site: testSite{hugo: "[permalinks]\n  post = \"/:filename/\"\n", contentDir: "content/post", permalink: "hugo"},
+			file: "content/post/hello/world.md",
+			want: "world",
+		},
+		{
+			name: "pattern of another section with the file name",
+			site: testSite{hugo: "[permalinks]\n  post = \"/:slug/\"\n  notes = \"/:filename/\"\n", permalink: "hugo"},
+			file: "content/post/hello/world.md",
+			want: "hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.site.article(t, tt.file, tt.front)
+			if got := p.Slug(); got != tt.want {
+				t.Errorf("Slug() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
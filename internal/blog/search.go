@@ -0,0 +1,164 @@
+package blog
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Index is an in-memory full-text index of article bodies.
+type Index struct {
+	docs map[string]document
+}
+
+type document struct {
+	lines []string
+	// offset is the number of lines before the body, e.g. the front matter
+	offset int
+}
+
+// Match is a line matched in an article. Line is the line number in the file.
+type Match struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+type Result struct {
+	Article Article
+	Matches []Match
+	Score   int
+}
+
+func NewIndex() *Index {
+	return &Index{docs: map[string]document{}}
+}
+
+func (idx *Index) add(path string, body []byte, offset int) {
+	idx.docs[path] = document{
+		lines:  strings.Split(string(body), "\n"),
+		offset: offset,
+	}
+}
+
+// Search finds the articles containing every word of the query
+// (case-insensitive) in their title or body, ranked by relevance.
+func (idx *Index) Search(articles []Article, query string) []Result {
+	// a repeated word counts once
+	terms := slices.Compact(slices.Sorted(slices.Values(strings.Fields(strings.ToLower(query)))))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []Result
+	for _, article := range articles {
+		doc := idx.docs[article.Path]
+		title := strings.ToLower(article.Meta.Title)
+		found := make(map[string]bool, len(terms))
+		var score int
+		for _, term := range terms {
+			if strings.Contains(title, term) {
+				// a match in the title weighs more than in the body
+				score += 10
+				found[term] = true
+			}
+		}
+		var matches []Match
+		for i, line := range doc.lines {
+			lower := strings.ToLower(line)
+			matched := false
+			for _, term := range terms {
+				if n := strings.Count(lower, term); n > 0 {
+					score += n
+					found[term] = true
+					matched = true
+				}
+			}
+			if matched {
+				matches = append(matches, Match{Line: doc.offset + i + 1, Text: line})
+			}
+		}
+		if len(found) < len(terms) {
+			continue
+		}
+		results = append(results, Result{Article: article, Matches: matches, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// Grep finds the lines matching the pattern in the article bodies.
+func (idx *Index) Grep(articles []Article, re *regexp.Regexp) []Result {
+	var results []Result
+	for _, article := range articles {
+		doc := idx.docs[article.Path]
+		var matches []Match
+		for i, line := range doc.lines {
+			if re.MatchString(line) {
+				matches = append(matches, Match{Line: doc.offset + i + 1, Text: line})
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		results = append(results, Result{Article: article, Matches: matches, Score: len(matches)})
+	}
+	return results
+}
+
+// Snippet returns the text around the first occurrence of the query
+// within about width characters.
+func Snippet(text, query string, width int) string {
+	text = strings.TrimSpace(text)
+	pos := -1
+	for _, term := range strings.Fields(query) {
+		if i := indexFold(text, term); i >= 0 && (pos < 0 || i < pos) {
+			pos = i
+		}
+	}
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	start := 0
+	if pos > 0 {
+		// keep some context before the match
+		start = max(utf8.RuneCountInString(text[:pos])-width/4, 0)
+	}
+	end := min(start+width, len(runes))
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// indexFold returns the index of the first instance of substr in s with
+// the case ignored, or -1. Unlike searching the lowercased s, the index is
+// the one in s even when a letter changes its length by the case, e.g. İ.
+func indexFold(s, substr string) int {
+	for i := range s {
+		if hasPrefixFold(s[i:], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	for _, r := range prefix {
+		c, size := utf8.DecodeRuneInString(s)
+		if size == 0 || !strings.EqualFold(string(c), string(r)) {
+			return false
+		}
+		s = s[size:]
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package blog
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		width int
+		want  string
+	}{
+		{
+			name:  "short text",
+			text:  "  Hello World  ",
+			query: "world",
+			width: 20,
+			want:  "Hello World",
+		},
+		{
+			name:  "case ignored",
+			text:  strings.Repeat("a", 30) + " Needle " + strings.Repeat("b", 30),
+			query: "NEEDLE",
+			width: 12,
+			want:  "…aa Needle bb…",
+		},
+		{
+			name:  "letters changing the length by the case",
+			text:  strings.Repeat("İ", 30) + " needle " + strings.Repeat("b", 30),
+			query: "needle",
+			width: 12,
+			want:  "…İİ needle bb…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(tt.text, tt.query, tt.width); got != tt.want {
+				t.Errorf("Snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := NewIndex()
+	articles := []Article{
+		{Meta: Meta{Title: "Go"}, Path: "go.md"},
+		{Meta: Meta{Title: "Rust"}, Path: "rust.md"},
+	}
+	idx.add("go.md", []byte("Go and go modules"), 3)
+	idx.add("rust.md", []byte("cargo"), 3)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "go", want: []string{"go.md", "rust.md"}},
+		{query: "go go", want: []string{"go.md", "rust.md"}},
+		{query: "GO modules", want: []string{"go.md"}},
+		{query: "go python", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []string
+			for _, r := range idx.Search(articles, tt.query) {
+				got = append(got, r.Article.Path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
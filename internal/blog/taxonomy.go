@@ -0,0 +1,85 @@
+package blog
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Term is a tag or a category with the number of articles using it.
+type Term struct {
+	Name  string
+	Count int
+}
+
+// Tags returns the tags of the articles, the most used first.
+func Tags(articles []Article) []Term {
+	return terms(articles, func(p Article) []string { return p.Meta.Tags })
+}
+
+// Categories returns the categories of the articles, the most used first.
+func Categories(articles []Article) []Term {
+	return terms(articles, func(p Article) []string { return p.Meta.Categories })
+}
+
+func terms(articles []Article, get func(Article) []string) []Term {
+	var list []Term
+	index := map[string]int{}
+	for _, article := range articles {
+		for _, name := range get(article) {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if i, ok := index[name]; ok {
+				list[i].Count++
+				continue
+			}
+			index[name] = len(list)
+			list = append(list, Term{Name: name, Count: 1})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// SetTaxonomies replaces the tags and the categories in the front matter
+// of the article. The fields not changed are left alone, and no empty one
+// is added. The rest of the file is kept as it is.
+func (p Article) SetTaxonomies(tags, categories []string) error {
+	page, err := ReadPage(p.Path)
+	if err != nil {
+		return err
+	}
+	fm := page.FrontMatter
+	changed := false
+	for _, field := range []struct {
+		key    string
+		values []string
+		get    func() []string
+		set    func([]string) error
+	}{
+		{"tags", tags, fm.Tags, fm.SetTags},
+		{"categories", categories, fm.Categories, fm.SetCategories},
+	} {
+		if slices.Equal(field.get(), field.values) || len(field.values) == 0 && !fm.Has(field.key) {
+			continue
+		}
+		if field.values == nil {
+			field.values = []string{}
+		}
+		if err := field.set(field.values); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return page.WriteFile(p.Path)
+}
@@ -0,0 +1,60 @@
+package blog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetTaxonomies(t *testing.T) {
+	const data = "---\ntitle: Hello\ntags: [go]\n---\nbody\n"
+	tests := []struct {
+		name       string
+		data       string
+		tags       []string
+		categories []string
+		want       string
+	}{
+		{
+			name: "unchanged",
+			data: data,
+			tags: []string{"go"},
+			want: data,
+		},
+		{
+			name: "no empty key added",
+			data: data,
+			tags: []string{"go", "cli"},
+			want: "---\ntitle: Hello\ntags: [go, cli]\n---\nbody\n",
+		},
+		{
+			name: "emptied",
+			data: data,
+			want: "---\ntitle: Hello\ntags: []\n---\nbody\n",
+		},
+		{
+			name:       "added",
+			data:       data,
+			tags:       []string{"go"},
+			categories: []string{"tech"},
+			want:       "---\ntitle: Hello\ntags: [go]\ncategories:\n  - tech\n---\nbody\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "index.md", tt.data)
+			p := Article{Path: filepath.Join(dir, "index.md")}
+			if err := p.SetTaxonomies(tt.tags, tt.categories); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(p.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/babarot/blog/internal/config"
+	"github.com/babarot/blog/internal/editor"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+type configCmd struct {
+	json    bool
+	project bool
+	force   bool
+}
+
+func newConfigCmd() *cobra.Command {
+	c := &configCmd{}
+
+	configCmd := &cobra.Command{
+		Use:                   "config",
+		Short:                 "Manage the config",
+		Aliases:               []string{},
+		GroupID:               "sub",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+		// the config may be missing or broken here, so do not load it
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			_, err := setupLogger()
+			return err
+		},
+	}
+
+	initCmd := &cobra.Command{
+		Use:                   "init",
+		Short:                 "Create the config interactively",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.init()
+		},
+	}
+	initCmd.Flags().BoolVarP(&c.project, "project", "p", false, "create "+config.ProjectFile+" in the hugo root instead")
+	initCmd.Flags().BoolVarP(&c.force, "force", "f", false, "overwrite the existing config")
+
+	showCmd := &cobra.Command{
+		Use:                   "show",
+		Short:                 "Show the effective config",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.show(os.Stdout)
+		},
+	}
+	showCmd.Flags().BoolVarP(&c.json, "json", "", false, "output in JSON")
+
+	validateCmd := &cobra.Command{
+		Use:                   "validate",
+		Short:                 "Report all the problems of the config",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.validate()
+		},
+	}
+
+	editCmd := &cobra.Command{
+		Use:                   "edit",
+		Short:                 "Open the config in the editor",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.edit()
+		},
+	}
+	editCmd.Flags().BoolVarP(&c.project, "project", "p", false, "open the project config instead")
+
+	pathCmd := &cobra.Command{
+		Use:                   "path",
+		Short:                 "Print the paths of the config files",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.path()
+		},
+	}
+
+	configCmd.AddCommand(initCmd, showCmd, validateCmd, editCmd, pathCmd)
+	return configCmd
+}
+
+func (c *configCmd) show(w io.Writer) error {
+	cfg, err := readConfig()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if !c.json {
+		_, err := w.Write(data)
+		return err
+	}
+	// go through yaml to use the same keys as the config file
+	var v map[string]any
+	if err := yaml3.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return printJSON(w, v)
+}
+
+func (c *configCmd) validate() error {
+	cfg, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	// check every site as well as the one selected
+	var errs []error
+	if cfg.SiteName == "" {
+		errs = append(errs, unjoin(config.Validate(cfg))...)
+	}
+	for _, name := range cfg.SiteNames() {
+		site, err := cfg.WithSite(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, err := range unjoin(config.Validate(site)) {
+			errs = append(errs, fmt.Errorf("site %s: %w", name, err))
+		}
+	}
+
+	if len(errs) == 0 {
+		fmt.Println("config is valid")
+		return nil
+	}
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	return fmt.Errorf("%d problem(s) found in the config", len(errs))
+}
+
+// unjoin returns the errors joined by errors.Join.
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+func (c *configCmd) path() error {
+	fmt.Println(configPath)
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if _, file, ok := config.FindProject(wd); ok && file != "" && file != configPath {
+		fmt.Println(file)
+	}
+	return nil
+}
+
+func (c *configCmd) edit() error {
+	path := configPath
+	if c.project {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		_, file, ok := config.FindProject(wd)
+		if !ok || file == "" {
+			return errors.New("no project config found, create it with \"blog config init --project\"")
+		}
+		path = file
+	}
+
+	// the config may be broken, so fall back on the environment
+	editorName, editorEnv := defaultEditor(), map[string]string(nil)
+	if cfg, err := readConfig(); err == nil && cfg.Editor != "" {
+		editorName, editorEnv = cfg.Editor, cfg.EditorEnv
+	}
+	cmd, err := editor.Open(editorName, editorEnv, path, 0)
+	if err != nil {
+		return err
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", editorName, err)
+	}
+	return c.validate()
+}
+
+func (c *configCmd) init() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, _, ok := config.FindProject(wd)
+	if !ok {
+		root = wd
+	}
+
+	cfg := config.Default()
+	cfg.Hugo.RootDir = root
+	cfg.Hugo.ContentDir = "content"
+	cfg.Blog.Name = filepath.Base(root)
+	cfg.Editor = defaultEditor()
+	if site, err := config.ReadHugoSite(root); err == nil {
+		if site.Title != "" {
+			cfg.Blog.Name = site.Title
+		}
+		if site.BaseURL != "" {
+			cfg.Blog.URL = site.BaseURL
+		}
+		if site.ContentDir != "" {
+			cfg.Hugo.ContentDir = site.ContentDir
+		}
+	}
+
+	path := configPath
+	if c.project {
+		path = filepath.Join(root, config.ProjectFile)
+	}
+	overwrite := true
+	_, statErr := os.Stat(path)
+	exists := statErr == nil && !c.force
+
+	devPort := strconv.Itoa(cfg.Blog.DevPort)
+	fields := []huh.Field{
+		huh.NewInput().Title("Name of the blog").Value(&cfg.Blog.Name),
+		huh.NewInput().Title("URL of the blog").Value(&cfg.Blog.URL),
+	}
+	if !c.project {
+		// the project config takes its directory as the root
+		fields = append(fields, huh.NewInput().Title("Hugo root directory").Value(&cfg.Hugo.RootDir))
+	}
+	fields = append(fields,
+		huh.NewInput().Title("Content directory (relative to the root)").Value(&cfg.Hugo.ContentDir),
+		huh.NewInput().Title("Hugo server command").Value(&cfg.Hugo.Command),
+		huh.NewInput().Title("Port of the hugo server").Value(&devPort).
+			Validate(func(s string) error {
+				_, err := strconv.Atoi(s)
+				return err
+			}),
+	)
+	if !c.project {
+		fields = append(fields, huh.NewInput().
+			Title("Editor").
+			Description("e.g. vim, code --wait, nvim +{{.Line}} {{.Path}}").
+			Value(&cfg.Editor))
+	}
+	groups := []*huh.Group{huh.NewGroup(fields...)}
+	if exists {
+		groups = append(groups, huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("%s already exists. Overwrite it?", path)).
+				Affirmative("Yes!").
+				Negative("No.").
+				Value(&overwrite),
+		))
+	}
+	if err := huh.NewForm(groups...).Run(); err != nil {
+		return err
+	}
+	if !overwrite {
+		return errors.New("canceled")
+	}
+	cfg.Blog.DevPort, _ = strconv.Atoi(devPort)
+
+	var v any = cfg
+	if c.project {
+		p := projectConfig{Blog: cfg.Blog}
+		p.Hugo.Command = cfg.Hugo.Command
+		p.Hugo.ContentDir = cfg.Hugo.ContentDir
+		v = p
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	fmt.Println("created", path)
+	return nil
+}
+
+// projectConfig is written to the project config, leaving the personal
+// settings such as the editor to the global config.
+type projectConfig struct {
+	Blog config.Blog `yaml:"blog"`
+	Hugo struct {
+		Command    string `yaml:"command"`
+		ContentDir string `yaml:"content_dir"`
+	} `yaml:"hugo"`
+}
+
+// defaultEditor returns the editor of the user or the first one found.
+func defaultEditor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if e := os.Getenv(name); e != "" {
+			return e
+		}
+	}
+	for _, name := range []string{"nvim", "vim", "vi", "nano"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return "vim"
+}
@@ -2,11 +2,12 @@ package cmd
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 
+	"github.com/babarot/blog/internal/blog"
 	"github.com/babarot/blog/internal/config"
-	"github.com/babarot/blog/internal/shell"
+	"github.com/babarot/blog/internal/env"
+	"github.com/babarot/blog/internal/hugo"
 	"github.com/babarot/blog/internal/ui"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -15,9 +16,11 @@ import (
 type editCmd struct {
 	config config.Config
 
-	tags    bool
-	noTags  bool
-	noDraft bool
+	tags       bool
+	noTags     bool
+	noDraft    bool
+	tagNames   []string
+	categories []string
 }
 
 func newEditCmd() *cobra.Command {
@@ -32,6 +35,7 @@ func newEditCmd() *cobra.Command {
 		SilenceUsage:          true,
 		SilenceErrors:         true,
 		Args:                  cobra.MaximumNArgs(0),
+		Annotations:           map[string]string{validConfig: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := cmd.Context().Value(config.Key).(config.Config)
 			c.config = cfg
@@ -43,6 +47,9 @@ func newEditCmd() *cobra.Command {
 	f.BoolVarP(&c.tags, "with-tags", "t", false, "with tags")
 	f.BoolVarP(&c.noTags, "no-tags", "", false, "with no tags")
 	f.BoolVarP(&c.noDraft, "no-draft", "", false, "with not in draft")
+	f.StringArrayVarP(&c.tagNames, "tag", "", []string{}, "with the given tag (can be repeated)")
+	f.StringArrayVarP(&c.categories, "category", "", []string{}, "with the given category (can be repeated)")
+	editCmd.MarkFlagsMutuallyExclusive("with-tags", "no-tags")
 
 	return editCmd
 }
@@ -51,31 +58,27 @@ func (c *editCmd) run(args []string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	p := tea.NewProgram(ui.Init(c.config))
+	filter := blog.Filter{
+		WithTags:   c.tags,
+		NoTags:     c.noTags,
+		NoDraft:    c.noDraft,
+		Tags:       c.tagNames,
+		Categories: c.categories,
+	}
 
-	hugo := shell.Shell{
-		Command: c.config.Hugo.Command,
-		Dir:     c.config.Hugo.RootDir,
-		Stdout:  c.config.LogWriter,
-		Stderr:  c.config.LogWriter,
+	sites := make(chan site)
+	model := ui.Init(c.config, filter)
+	model.OnSiteChange = func(cfg config.Config, generation int) {
+		select {
+		case sites <- site{config: cfg, generation: generation}:
+		case <-ctx.Done():
+		}
 	}
+	p := tea.NewProgram(model)
 
 	done := make(chan error)
 	go func() {
-		p.Send(ui.HugoServerMsg{Text: "hugo server is running in background!", Type: ui.ToastInfo})
-		err := hugo.Run(ctx)
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				slog.Debug("hugo canceled")
-				done <- nil
-				return
-			}
-			p.Send(ui.HugoServerMsg{Text: "hugo server failed...", Type: ui.ToastWarn})
-			slog.Error("hugo failed", "error", err)
-		} else {
-			slog.Debug("hugo finished")
-		}
-		done <- err
+		done <- c.serve(ctx, site{config: c.config}, sites, p)
 	}()
 
 	if _, err := p.Run(); err != nil {
@@ -86,9 +89,66 @@ func (c *editCmd) run(args []string) error {
 	cancel()
 	// wait for stopping hugo
 	if err := <-done; err != nil {
-		slog.Error("error failed", "error", err)
 		return err
 	}
 
 	return nil
 }
+
+// site is the site switched to in the UI with the generation of it.
+type site struct {
+	config     config.Config
+	generation int
+}
+
+// serve runs the hugo server of the site and restarts it for the site
+// switched to in the UI until ctx is canceled.
+func (c *editCmd) serve(ctx context.Context, current site, sites <-chan site, p *tea.Program) error {
+	for {
+		cfg, generation := current.config, current.generation
+		serverCtx, cancel := context.WithCancel(ctx)
+		server := &hugo.Server{
+			Command:  cfg.Hugo.Command,
+			Dir:      cfg.Hugo.RootDir,
+			Port:     cfg.Blog.DevPort,
+			Env:      cfg.Hugo.Env,
+			StateDir: env.BLOG_STATE_DIR,
+			Log:      c.config.LogWriter,
+			OnStatus: func(status hugo.Status) {
+				p.Send(ui.HugoStatusMsg{Status: status, Generation: generation})
+			},
+			OnEvent: func(ev hugo.Event) {
+				p.Send(ui.HugoEventMsg{Event: ev, Generation: generation})
+			},
+		}
+		exited := make(chan error, 1)
+		go func() {
+			err := server.Run(serverCtx)
+			if err != nil {
+				slog.Error("hugo failed", "error", err)
+			} else {
+				slog.Debug("hugo finished")
+			}
+			exited <- err
+		}()
+
+		select {
+		case err := <-exited:
+			// keep the error until the UI finishes or switches the site
+			select {
+			case <-ctx.Done():
+				cancel()
+				return err
+			case current = <-sites:
+			}
+		case current = <-sites:
+			cancel()
+			<-exited
+		case <-ctx.Done():
+			cancel()
+			return <-exited
+		}
+		cancel()
+		slog.Debug("restart hugo for the site", "site", current.config.SiteName)
+	}
+}
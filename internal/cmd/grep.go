@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/babarot/blog/internal/blog"
+	"github.com/babarot/blog/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var errNoMatch = errors.New("no matches found")
+
+type grepCmd struct {
+	config config.Config
+
+	ignoreCase bool
+	json       bool
+	noDraft    bool
+}
+
+type grepRecord struct {
+	Title   string       `json:"title"`
+	Slug    string       `json:"slug"`
+	Path    string       `json:"path"`
+	Matches []blog.Match `json:"matches"`
+}
+
+func newGrepCmd() *cobra.Command {
+	c := &grepCmd{}
+
+	grepCmd := &cobra.Command{
+		Use:                   "grep <pattern>",
+		Short:                 "Search article bodies for a pattern",
+		Aliases:               []string{},
+		GroupID:               "main",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.ExactArgs(1),
+		Annotations:           map[string]string{validConfig: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := cmd.Context().Value(config.Key).(config.Config)
+			c.config = cfg
+			return c.run(args)
+		},
+	}
+
+	f := grepCmd.Flags()
+	f.BoolVarP(&c.ignoreCase, "ignore-case", "i", false, "ignore case distinctions")
+	f.BoolVarP(&c.json, "json", "", false, "print as a JSON array")
+	f.BoolVarP(&c.noDraft, "no-draft", "", false, "with not in draft")
+
+	return grepCmd
+}
+
+func (c *grepCmd) run(args []string) error {
+	pattern := args[0]
+	if c.ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	b, err := blog.Load(c.config)
+	if err != nil {
+		return err
+	}
+	articles := blog.Filter{NoDraft: c.noDraft}.Apply(b.Articles)
+	results := b.Index.Grep(articles, re)
+
+	if c.json {
+		records := make([]grepRecord, 0, len(results))
+		for _, r := range results {
+			records = append(records, grepRecord{
+				Title:   r.Article.Meta.Title,
+				Slug:    r.Article.Slug(),
+				Path:    r.Article.Path,
+				Matches: r.Matches,
+			})
+		}
+		if err := printJSON(os.Stdout, records); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			for _, m := range r.Matches {
+				fmt.Printf("%s:%d:%s\n", r.Article.Path, m.Line, m.Text)
+			}
+		}
+	}
+	if len(results) == 0 {
+		// behave like grep(1) so that scripts can check the exit status
+		return errNoMatch
+	}
+	return nil
+}
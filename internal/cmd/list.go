@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/babarot/blog/internal/blog"
+	"github.com/babarot/blog/internal/config"
+	"github.com/babarot/blog/internal/env"
+	"github.com/babarot/blog/internal/hugo"
+	"github.com/spf13/cobra"
+)
+
+type listCmd struct {
+	config config.Config
+
+	json       bool
+	jsonl      bool
+	format     string
+	draftOnly  bool
+	noDraft    bool
+	since      string
+	until      string
+	sort       string
+	tagNames   []string
+	categories []string
+}
+
+// articleRecord is the printable form of blog.Article.
+type articleRecord struct {
+	Title      string    `json:"title"`
+	Slug       string    `json:"slug"`
+	Date       time.Time `json:"date"`
+	Draft      bool      `json:"draft"`
+	Tags       []string  `json:"tags"`
+	Categories []string  `json:"categories"`
+	Path       string    `json:"path"`
+	URL        string    `json:"url"`
+	DevURL     string    `json:"dev_url"`
+}
+
+func newListCmd() *cobra.Command {
+	c := &listCmd{}
+
+	listCmd := &cobra.Command{
+		Use:                   "list",
+		Short:                 "List articles",
+		Aliases:               []string{"ls"},
+		GroupID:               "main",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(0),
+		Annotations:           map[string]string{validConfig: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := cmd.Context().Value(config.Key).(config.Config)
+			c.config = cfg
+			return c.run(args)
+		},
+	}
+
+	f := listCmd.Flags()
+	f.BoolVarP(&c.json, "json", "", false, "print as a JSON array")
+	f.BoolVarP(&c.jsonl, "jsonl", "", false, "print as JSON lines")
+	f.StringVarP(&c.format, "format", "f", "", "print with a Go template, e.g. '{{.Slug}}\\t{{.URL}}'")
+	f.BoolVarP(&c.draftOnly, "draft-only", "", false, "with only in draft")
+	f.BoolVarP(&c.noDraft, "no-draft", "", false, "with not in draft")
+	f.StringVarP(&c.since, "since", "", "", "with date on or after YYYY-MM-DD")
+	f.StringVarP(&c.until, "until", "", "", "with date on or before YYYY-MM-DD")
+	f.StringVarP(&c.sort, "sort", "s", "date", "sort by date, title or slug")
+	f.StringArrayVarP(&c.tagNames, "tag", "", []string{}, "with the given tag (can be repeated)")
+	f.StringArrayVarP(&c.categories, "category", "", []string{}, "with the given category (can be repeated)")
+	listCmd.MarkFlagsMutuallyExclusive("json", "jsonl", "format")
+	listCmd.MarkFlagsMutuallyExclusive("draft-only", "no-draft")
+
+	return listCmd
+}
+
+func (c *listCmd) run(args []string) error {
+	filter := blog.Filter{
+		NoDraft:    c.noDraft,
+		DraftOnly:  c.draftOnly,
+		Tags:       c.tagNames,
+		Categories: c.categories,
+	}
+	// the days are in UTC as the dates of the articles (see blog.ParseDate)
+	if c.since != "" {
+		since, err := time.Parse("2006-01-02", c.since)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		filter.Since = since
+	}
+	if c.until != "" {
+		until, err := time.Parse("2006-01-02", c.until)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		// include the whole day
+		filter.Until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	articles, err := blog.Posts(c.config)
+	if err != nil {
+		return err
+	}
+	articles = filter.Apply(articles)
+
+	switch c.sort {
+	case "date":
+		// blog.Posts already sorts by date, newest first
+	case "title":
+		sort.SliceStable(articles, func(i, j int) bool {
+			return articles[i].Meta.Title < articles[j].Meta.Title
+		})
+	case "slug":
+		sort.SliceStable(articles, func(i, j int) bool {
+			return articles[i].Slug() < articles[j].Slug()
+		})
+	default:
+		return fmt.Errorf("invalid --sort %q: must be one of date, title or slug", c.sort)
+	}
+
+	// point dev_url to the hugo server if it is running on another port
+	var devPort int
+	if inst, err := hugo.Lookup(env.BLOG_STATE_DIR, c.config.Hugo.RootDir); err == nil && inst != nil {
+		devPort = inst.Port
+	}
+
+	records := make([]articleRecord, 0, len(articles))
+	for _, article := range articles {
+		records = append(records, newArticleRecord(article.WithDevPort(devPort)))
+	}
+
+	w := os.Stdout
+	switch {
+	case c.json:
+		return printJSON(w, records)
+	case c.jsonl:
+		return printJSONLines(w, records)
+	case c.format != "":
+		return printTemplate(w, c.format, records)
+	default:
+		return printTable(w, records)
+	}
+}
+
+func newArticleRecord(p blog.Article) articleRecord {
+	tags := p.Meta.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	categories := p.Meta.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	return articleRecord{
+		Title:      p.Meta.Title,
+		Slug:       p.Slug(),
+		Date:       p.Date,
+		Draft:      p.Meta.Draft,
+		Tags:       tags,
+		Categories: categories,
+		Path:       p.Path,
+		URL:        p.URL(),
+		DevURL:     p.DevURL(),
+	}
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printJSONLines[T any](w io.Writer, records []T) error {
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printTemplate[T any](w io.Writer, format string, records []T) error {
+	// allow escaped tabs and newlines to be given from the shell
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	tmpl, err := template.New("format").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+	for _, record := range records {
+		if err := tmpl.Execute(w, record); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func printTable(w io.Writer, records []articleRecord) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tSLUG\tTITLE\tDRAFT\tTAGS\tCATEGORIES\tURL")
+	for _, r := range records {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\t%s\n",
+			r.Date.Format("2006-01-02"),
+			r.Slug,
+			r.Title,
+			r.Draft,
+			strings.Join(r.Tags, ","),
+			strings.Join(r.Categories, ","),
+			r.URL,
+		)
+	}
+	return tw.Flush()
+}
@@ -8,19 +8,46 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 	"unicode"
 
 	"github.com/babarot/blog/internal/blog"
 	"github.com/babarot/blog/internal/config"
+	"github.com/babarot/blog/internal/editor"
+	"github.com/babarot/blog/internal/env"
+	"github.com/babarot/blog/internal/hugo"
 	"github.com/babarot/blog/internal/shell"
+	"github.com/babarot/blog/internal/slug"
+	"github.com/babarot/blog/internal/ui"
 	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
+	"golang.org/x/term"
 )
 
 type newCmd struct {
 	config config.Config
+
+	slug       string
+	title      string
+	kind       string
+	toc        bool
+	tags       []string
+	categories []string
+	draft      bool
+	date       string
+	noEdit     bool
+
+	askTOC        bool
+	interactive   bool
+	draftSet      bool
+	tagsSet       bool
+	categoriesSet bool
+
+	// articles are the existing ones to check the slug against
+	articles []blog.Article
 }
 
 func newNewCmd() *cobra.Command {
@@ -35,127 +62,414 @@ func newNewCmd() *cobra.Command {
 		SilenceUsage:          true,
 		SilenceErrors:         true,
 		Args:                  cobra.MaximumNArgs(0),
+		Annotations:           map[string]string{validConfig: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := cmd.Context().Value(config.Key).(config.Config)
 			c.config = cfg
+			c.askTOC = !cmd.Flags().Changed("toc")
+			c.interactive = term.IsTerminal(int(os.Stdin.Fd()))
+			c.draftSet = cmd.Flags().Changed("draft")
+			c.tagsSet = cmd.Flags().Changed("tags")
+			c.categoriesSet = cmd.Flags().Changed("categories")
 			return c.run(args)
 		},
 	}
 
+	f := newCmd.Flags()
+	f.StringVarP(&c.slug, "slug", "s", "", "slug of the article")
+	f.StringVarP(&c.title, "title", "t", "", "title of the article")
+	f.StringVarP(&c.kind, "kind", "k", "", "archetype of the article, e.g. post (default: asked if there are many)")
+	f.BoolVarP(&c.toc, "toc", "", false, "show table of contents")
+	f.StringSliceVarP(&c.tags, "tags", "", []string{}, "comma-separated tags")
+	f.StringSliceVarP(&c.categories, "categories", "", []string{}, "comma-separated categories")
+	f.BoolVarP(&c.draft, "draft", "", false, "create as a draft")
+	f.StringVarP(&c.date, "date", "", "", "date of the article, e.g. 2006-01-02 (default: now)")
+	f.BoolVarP(&c.noEdit, "no-edit", "", false, "create the article without opening the editor")
+
 	return newCmd
 }
 
-func (c *newCmd) run(args []string) error {
-	var (
-		slug  string
-		title string
-		toc   bool
-	)
-
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().
-				Title("What’s for slug?").
-				Prompt("? ").
-				Validate(func(s string) error {
-					re := regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
-					if re.MatchString(s) {
-						return nil
-					}
-					return errors.New("invalid chars included for slug")
-				}).
-				Value(&slug),
-			huh.NewInput().
-				Title("What’s for title?").
-				Prompt("? ").
-				Validate(func(s string) error {
-					isAllowed := func(s string) bool {
-						for _, ch := range s {
-							if !(unicode.IsLetter(ch) ||
-								unicode.IsDigit(ch) ||
-								unicode.IsSpace(ch) ||
-								unicode.IsPunct(ch) ||
-								unicode.IsSymbol(ch)) {
-								return false
-							}
-						}
-						return true
-					}
-					if isAllowed(s) {
-						return nil
-					}
-					return errors.New("invalid chars included for title")
-				}).
-				Value(&title),
-		),
-		huh.NewGroup(
+func validateSlug(s string) error {
+	re := regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+	if re.MatchString(s) {
+		return nil
+	}
+	return errors.New("invalid chars included for slug")
+}
+
+func validateTitle(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return errors.New("title is empty")
+	}
+	for _, ch := range s {
+		if !(unicode.IsLetter(ch) ||
+			unicode.IsDigit(ch) ||
+			unicode.IsSpace(ch) ||
+			unicode.IsPunct(ch) ||
+			unicode.IsSymbol(ch)) {
+			return errors.New("invalid chars included for title")
+		}
+	}
+	return nil
+}
+
+// checkSlug validates the slug and refuses the one of an existing article.
+func (c *newCmd) checkSlug(s string) error {
+	if err := validateSlug(s); err != nil {
+		return err
+	}
+	if article, ok := c.findSlug(s); ok {
+		return fmt.Errorf("slug %q is already used by %s", s, article.Path)
+	}
+	return nil
+}
+
+func (c *newCmd) findSlug(s string) (blog.Article, bool) {
+	for _, article := range c.articles {
+		if article.Slug() == s {
+			return article, true
+		}
+	}
+	return blog.Article{}, false
+}
+
+// suggestSlug makes the slug from the title, which is not used yet.
+func (c *newCmd) suggestSlug(date time.Time) string {
+	s := slug.New(c.config.Slug.Dictionary)
+	return slug.Unique(slug.Make(s, c.title, date, c.config.Slug.Fallback), func(s string) bool {
+		_, ok := c.findSlug(s)
+		return ok
+	})
+}
+
+// archetypes returns the kinds of the archetypes of the site, which are
+// the files and the directories (for page bundles) in archetypes/.
+func archetypes(rootDir string) []string {
+	entries, err := os.ReadDir(filepath.Join(rootDir, "archetypes"))
+	if err != nil {
+		return nil
+	}
+	var kinds []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !entry.IsDir() {
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		if !slices.Contains(kinds, name) {
+			kinds = append(kinds, name)
+		}
+	}
+	return kinds
+}
+
+// ask fills in the values not given by flags with an interactive form.
+// Without a terminal, e.g. in a cron job, nothing is asked: the title is
+// required, the slug is made from it and hugo chooses the archetype.
+func (c *newCmd) ask(date time.Time) error {
+	kinds := archetypes(c.config.Hugo.RootDir)
+	if c.kind != "" && len(kinds) > 0 && !slices.Contains(kinds, c.kind) {
+		return fmt.Errorf("unknown kind %q (available: %s)", c.kind, strings.Join(kinds, ", "))
+	}
+	if !c.interactive {
+		if c.title == "" {
+			return errors.New("--title is required when stdin is not a terminal")
+		}
+		if err := validateTitle(c.title); err != nil {
+			return err
+		}
+		if c.slug == "" {
+			c.slug = c.suggestSlug(date)
+		}
+		return c.checkSlug(c.slug)
+	}
+
+	var inputs []huh.Field
+	// the kind is asked only along with the other values
+	if c.kind == "" && len(kinds) > 1 && (c.title == "" || c.slug == "") {
+		inputs = append(inputs, huh.NewSelect[string]().
+			Title("What kind of article?").
+			Options(huh.NewOptions(kinds...)...).
+			Value(&c.kind))
+	}
+	if c.title == "" {
+		inputs = append(inputs, huh.NewInput().
+			Title("What’s for title?").
+			Prompt("? ").
+			Validate(validateTitle).
+			Value(&c.title))
+	} else if err := validateTitle(c.title); err != nil {
+		return err
+	}
+	askSlug := c.slug == ""
+	if askSlug {
+		inputs = append(inputs, huh.NewInput().
+			Title("What’s for slug?").
+			Description("Leave it empty to take the one made from the title").
+			Prompt("? ").
+			PlaceholderFunc(func() string { return c.suggestSlug(date) }, &c.title).
+			Validate(func(s string) error {
+				if s == "" {
+					return nil
+				}
+				return c.checkSlug(s)
+			}).
+			Value(&c.slug))
+	} else if err := c.checkSlug(c.slug); err != nil {
+		return err
+	}
+
+	var groups []*huh.Group
+	if len(inputs) > 0 {
+		groups = append(groups, huh.NewGroup(inputs...))
+	}
+	if c.askTOC && len(groups) > 0 {
+		groups = append(groups, huh.NewGroup(
 			huh.NewConfirm().
 				Title("Show table of contents?").
 				Affirmative("Yes!").
 				Negative("No.").
-				Value(&toc),
-		),
-	)
-	if err := form.Run(); err != nil {
+				Value(&c.toc),
+		))
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	if err := huh.NewForm(groups...).Run(); err != nil {
 		return err
 	}
+	if askSlug && c.slug == "" {
+		c.slug = c.suggestSlug(date)
+	}
+	return c.askTaxonomies()
+}
 
-	date := time.Now().Format("2006-01-02T15:04:05-07:00")
-	year := time.Now().Year()
-	meta := blog.Meta{
-		Title: title,
-		Toc:   toc,
-		Date:  date,
+// askTaxonomies picks the tags and categories not given by flags from the
+// ones used in the other articles. Canceling it leaves them empty.
+func (c *newCmd) askTaxonomies() error {
+	var fields []ui.TermsModel
+	if !c.tagsSet {
+		fields = append(fields, ui.NewTerms("Tags", blog.Tags(c.articles), c.tags))
 	}
-	mdFile := fmt.Sprintf("%s/%d/%s/index.md", c.config.Hugo.ContentDir, year, slug)
+	if !c.categoriesSet {
+		fields = append(fields, ui.NewTerms("Categories", blog.Categories(c.articles), c.categories))
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	values, err := ui.EditTaxonomy(fields...)
+	if errors.Is(err, ui.ErrCanceled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !c.tagsSet {
+		c.tags, values = values[0], values[1:]
+	}
+	if !c.categoriesSet {
+		c.categories = values[0]
+	}
+	return nil
+}
 
-	hugo := shell.Shell{
-		Command: "hugo new " + mdFile,
-		Dir:     c.config.Hugo.RootDir,
-		Stdout:  c.config.LogWriter,
-		Stderr:  c.config.LogWriter,
+func (c *newCmd) setFrontMatter(fm *blog.FrontMatter, date time.Time) error {
+	if err := fm.SetTitle(c.title); err != nil {
+		return err
 	}
+	if err := fm.SetDate(date); err != nil {
+		return err
+	}
+	if err := fm.Set("toc", c.toc); err != nil {
+		return err
+	}
+	if c.draftSet {
+		if err := fm.SetDraft(c.draft); err != nil {
+			return err
+		}
+	}
+	if len(c.tags) > 0 {
+		if err := fm.SetTags(c.tags); err != nil {
+			return err
+		}
+	}
+	if len(c.categories) > 0 {
+		if err := fm.SetCategories(c.categories); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if err := hugo.Run(context.Background()); err != nil {
-		return fmt.Errorf("failed to run hugo new: %w", err)
+// pathData is the data of the hugo.new_path template.
+type pathData struct {
+	Date  time.Time
+	Year  string
+	Month string
+	Day   string
+	Slug  string
+	Title string
+	// Dir is content_dir in the content directory of hugo, e.g. post of
+	// content/post
+	Dir string
+
+	section string
+}
+
+// Section returns the section of the article. It fails when none is known
+// rather than rendering a path without it.
+func (d pathData) Section() (string, error) {
+	if d.section == "" {
+		return "", errors.New("hugo.new_path uses .Section but no section is known: pass --kind or add an archetype")
 	}
+	return d.section, nil
+}
+
+// section returns the kind of the article, or else the one of the only
+// archetype, or else the top directory of content_dir in the content
+// directory of hugo such as post of content/post.
+func (c *newCmd) section() string {
+	if c.kind != "" {
+		return c.kind
+	}
+	kinds := slices.DeleteFunc(archetypes(c.config.Hugo.RootDir), func(kind string) bool {
+		return kind == "default"
+	})
+	if len(kinds) == 1 {
+		return kinds[0]
+	}
+	dir, _, _ := strings.Cut(c.config.Hugo.ContentPath(), "/")
+	return dir
+}
 
-	data, err := yaml.Marshal(&meta)
+// newPath returns the path of the article in the content directory of hugo.
+func (c *newCmd) newPath(date time.Time) (string, error) {
+	text := c.config.Hugo.NewPath
+	if text == "" {
+		text = config.DefaultNewPath
+	}
+	tmpl, err := template.New("new_path").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid hugo.new_path: %w", err)
+	}
+	var b strings.Builder
+	err = tmpl.Execute(&b, pathData{
+		Date:    date,
+		Year:    date.Format("2006"),
+		Month:   date.Format("01"),
+		Day:     date.Format("02"),
+		Slug:    c.slug,
+		Title:   c.title,
+		Dir:     c.config.Hugo.ContentPath(),
+		section: c.section(),
+	})
 	if err != nil {
-		return fmt.Errorf("error marshalling to YAML: %w", err)
+		return "", fmt.Errorf("invalid hugo.new_path: %w", err)
 	}
+	// an empty .Dir leaves a leading slash
+	p := filepath.Clean(filepath.FromSlash(strings.TrimLeft(b.String(), "/")))
+	if filepath.IsAbs(p) || p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("hugo.new_path must be in the content directory: %s", p)
+	}
+	return p, nil
+}
 
-	mdPath := filepath.Join(c.config.Hugo.RootDir, mdFile)
-	file, err := os.Create(mdPath)
+func (c *newCmd) run(args []string) error {
+	// an article failing to parse should not keep from writing a new one
+	b, err := blog.LoadLenient(c.config)
 	if err != nil {
-		return fmt.Errorf("error creating file: %w", err)
+		return err
 	}
-	defer file.Close()
+	for _, err := range b.Errors {
+		slog.Warn("skipped an article", "error", err)
+	}
+	c.articles = b.Articles
 
-	frontMatter := fmt.Sprintf("---\n%s---\n", string(data))
-	_, err = file.Write([]byte(frontMatter))
+	date := time.Now()
+	if c.date != "" {
+		t, err := blog.ParseDate(c.date)
+		if err != nil {
+			return fmt.Errorf("invalid --date %q: %w", c.date, err)
+		}
+		date = t
+	}
+
+	if err := c.ask(date); err != nil {
+		return err
+	}
+	newPath, err := c.newPath(date)
+	if err != nil {
+		return err
+	}
+	mdPath := filepath.Join(c.config.Hugo.ContentRoot(), newPath)
+	mdFile, err := filepath.Rel(c.config.Hugo.RootDir, mdPath)
+	if err != nil {
+		mdFile = mdPath
+	}
+	if _, err := os.Stat(mdPath); err == nil {
+		return fmt.Errorf("%s already exists", mdPath)
+	}
+
+	hugoArgs := []string{"hugo", "new"}
+	if c.kind != "" {
+		hugoArgs = append(hugoArgs, "--kind", c.kind)
+	}
+	hugoNew := shell.Shell{
+		Args:   append(hugoArgs, mdFile),
+		Env:    c.config.Hugo.Env,
+		Dir:    c.config.Hugo.RootDir,
+		Stdout: c.config.LogWriter,
+		Stderr: c.config.LogWriter,
+	}
+
+	if err := hugoNew.Run(context.Background()); err != nil {
+		return fmt.Errorf("failed to run hugo new: %w", err)
+	}
+
+	// merge the values into the front matter generated from the archetype
+	page, err := blog.ReadPage(mdPath)
 	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", mdFile, err)
+	}
+	if page.FrontMatter.Format() == "" {
+		// the archetype has no front matter, so follow the other archetypes
+		page.FrontMatter = blog.NewFrontMatter(blog.ArchetypeFormat(c.config.Hugo.RootDir, c.kind))
+	}
+	if err := c.setFrontMatter(page.FrontMatter, date); err != nil {
+		return err
+	}
+	if err := page.WriteFile(mdPath); err != nil {
 		return fmt.Errorf("error writing to file: %w", err)
 	}
 
+	if c.noEdit {
+		fmt.Println(mdPath)
+		return nil
+	}
+
+	editorCmd, err := editor.Open(c.config.Editor, c.config.EditorEnv, mdPath, 0)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	server := &hugo.Server{
+		Command:  c.config.Hugo.Command,
+		Dir:      c.config.Hugo.RootDir,
+		Port:     c.config.Blog.DevPort,
+		Env:      c.config.Hugo.Env,
+		StateDir: env.BLOG_STATE_DIR,
+		Log:      c.config.LogWriter,
+	}
+
 	done := make(chan error)
 	go func() {
-		hugoServer := shell.Shell{
-			Command: c.config.Hugo.Command,
-			Dir:     c.config.Hugo.RootDir,
-			Stdout:  c.config.LogWriter,
-			Stderr:  c.config.LogWriter,
-		}
-		err := hugoServer.Run(ctx)
+		err := server.Run(ctx)
 		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				slog.Debug("hugo canceled")
-				done <- nil
-				return
-			}
 			slog.Error("hugo failed", "error", err)
 		} else {
 			slog.Debug("hugo finished")
@@ -164,7 +478,7 @@ func (c *newCmd) run(args []string) error {
 	}()
 
 	slog.Debug("running", "editor", c.config.Editor)
-	if err := shell.Command(c.config.Editor, mdPath).Run(); err != nil {
+	if err := editorCmd.Run(); err != nil {
 		return fmt.Errorf("failed to run %s: %w", c.config.Editor, err)
 	}
 
@@ -172,7 +486,6 @@ func (c *newCmd) run(args []string) error {
 	cancel()
 	// wait for stopping hugo
 	if err := <-done; err != nil {
-		slog.Error("error failed", "error", err)
 		return err
 	}
 
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/babarot/blog/internal/config"
+)
+
+// fakeHugo puts the hugo command creating the page of "hugo new" on PATH
+// and returns the file it records the arguments to.
+func fakeHugo(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake hugo is a shell script")
+	}
+	bin := t.TempDir()
+	args := filepath.Join(bin, "args")
+	script := `#!/bin/sh
+echo "$@" > ` + args + `
+[ "$1" = new ] || exit 1
+shift
+[ "$1" = --kind ] && shift 2
+mkdir -p "$(dirname "$1")"
+printf -- '---\ntitle: ""\n---\n' > "$1"
+`
+	if err := os.WriteFile(filepath.Join(bin, "hugo"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return args
+}
+
+// newSite makes a hugo site with the files and returns its root.
+func newSite(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range append([]string{"hugo.toml"}, files...) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("---\ntitle: \"\"\n---\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestNewNonInteractive(t *testing.T) {
+	args := fakeHugo(t)
+	root := newSite(t, "archetypes/default.md", "archetypes/post.md", "archetypes/note.md", "content/post/2020/old/index.md")
+	c := &newCmd{
+		config: config.Config{
+			LogWriter: io.Discard,
+			Hugo: config.Hugo{
+				RootDir:    root,
+				ContentDir: "content/post",
+				NewPath:    config.DefaultNewPath,
+			},
+		},
+		title:  "Cron",
+		slug:   "cron1",
+		date:   "2024-03-05",
+		noEdit: true,
+		askTOC: true,
+	}
+	if err := c.run(nil); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(root, "content", "post", "2024", "cron1", "index.md")
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("article is not created: %v", err)
+	}
+	data, err := os.ReadFile(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "--kind") {
+		t.Errorf("hugo new is given a kind: %s", data)
+	}
+}
+
+func TestNewNonInteractiveSlug(t *testing.T) {
+	fakeHugo(t)
+	root := newSite(t, "archetypes/default.md", "archetypes/post.md", "content/post/2020/old/index.md")
+	c := &newCmd{
+		config: config.Config{
+			LogWriter: io.Discard,
+			Hugo: config.Hugo{
+				RootDir:    root,
+				ContentDir: "content/post",
+				NewPath:    config.DefaultNewPath,
+			},
+		},
+		title:  "Weekly Digest",
+		noEdit: true,
+	}
+	if err := c.run(nil); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(root, "content", "post", time.Now().Format("2006"), "weekly-digest", "index.md")
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("article is not created: %v", err)
+	}
+
+	c = &newCmd{config: c.config, noEdit: true}
+	if err := c.run(nil); err == nil {
+		t.Error("no error without --title")
+	}
+}
+
+func TestNewPath(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		files      []string
+		hugo       string
+		contentDir string
+		newPath    string
+		kind       string
+		want       string
+	}{
+		{
+			name:       "default in a section",
+			contentDir: "content/post",
+			newPath:    config.DefaultNewPath,
+			want:       "content/post/2024/sec/index.md",
+		},
+		{
+			name:       "default in the content root",
+			contentDir: "content",
+			newPath:    config.DefaultNewPath,
+			want:       "content/2024/sec/index.md",
+		},
+		{
+			name:       "section of content_dir",
+			files:      []string{"archetypes/default.md", "archetypes/post.md", "archetypes/note.md"},
+			contentDir: "content/post",
+			newPath:    "{{.Section}}/{{.Slug}}.md",
+			want:       "content/post/sec.md",
+		},
+		{
+			name:       "section of the kind",
+			files:      []string{"archetypes/default.md", "archetypes/post.md", "archetypes/note.md"},
+			contentDir: "content/post",
+			newPath:    "{{.Section}}/{{.Slug}}.md",
+			kind:       "note",
+			want:       "content/note/sec.md",
+		},
+		{
+			name:       "contentDir of hugo",
+			hugo:       "contentDir = \"src\"\n",
+			contentDir: "src/post",
+			newPath:    "{{.Section}}/{{.Year}}/{{.Slug}}.md",
+			want:       "src/post/2024/sec.md",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newSite(t, append(tt.files, filepath.Join(tt.contentDir, ".keep"))...)
+			if err := os.WriteFile(filepath.Join(root, "hugo.toml"), []byte(tt.hugo), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c := &newCmd{
+				config: config.Config{
+					Hugo: config.Hugo{
+						RootDir:    root,
+						ContentDir: tt.contentDir,
+						NewPath:    tt.newPath,
+					},
+				},
+				slug: "sec",
+				kind: tt.kind,
+			}
+			got, err := c.newPath(date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ = filepath.Rel(root, filepath.Join(c.config.Hugo.ContentRoot(), got))
+			if filepath.ToSlash(got) != tt.want {
+				t.Errorf("newPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -31,6 +31,8 @@ var (
 
 var (
 	configPath string
+	siteName   string
+	sets       []string
 )
 
 func newRootCmd() *cobra.Command {
@@ -41,52 +43,12 @@ func newRootCmd() *cobra.Command {
 		DisableSuggestions: false,
 		Version:            fmt.Sprintf("%s (%s/%s)", version, revision, buildDate),
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
-			logDir := filepath.Dir(env.BLOG_LOG_PATH)
-			if _, err := os.Stat(logDir); os.IsNotExist(err) {
-				err := os.MkdirAll(logDir, 0755)
-				if err != nil {
-					return err
-				}
-			}
-
-			var w io.Writer
-			if file, err := os.OpenFile(env.BLOG_LOG_PATH, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
-				w = file
-			} else {
-				w = os.Stderr
-			}
-
-			logger := log.NewWithOptions(os.Stderr, log.Options{
-				ReportCaller:    true,
-				ReportTimestamp: true,
-				TimeFormat:      time.Kitchen,
-				Level:           log.DebugLevel,
-				Formatter: func() log.Formatter {
-					return log.TextFormatter
-				}(),
-			})
-			logger.SetOutput(w)
-			logger.With("run_id", runID())
-			slog.SetDefault(slog.New(logger))
-
-			defer slog.Debug("root command finished")
-			slog.Debug("root command started",
-				"version", version,
-				"revision", revision,
-				"go", runtime.Version(),
-				"args", os.Args,
-			)
-
-			c, err := config.Parse(configPath)
+			w, err := setupLogger()
 			if err != nil {
 				return err
 			}
-			c.LogWriter = w
-
-			ctx := context.WithValue(cmd.Context(), config.Key, c)
-			cmd.SetContext(ctx)
-
-			return nil
+			defer slog.Debug("root command finished")
+			return loadConfig(cmd, w)
 		},
 	}
 
@@ -99,13 +61,96 @@ func newRootCmd() *cobra.Command {
 	rootCmd.AddCommand(
 		newEditCmd(),
 		newNewCmd(),
+		newListCmd(),
+		newGrepCmd(),
 		newLogsCmd(),
+		newServerCmd(),
+		newConfigCmd(),
 	)
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", env.BLOG_CONFIG_PATH, "path to config")
+	rootCmd.PersistentFlags().StringVarP(&siteName, "site", "", "", "name of the site in the sites list of config")
+	rootCmd.PersistentFlags().StringArrayVarP(&sets, "set", "", []string{}, "override a config value, e.g. hugo.root_dir=~/blog (can be repeated)")
 
 	return rootCmd
 }
 
+// setupLogger sends the logs to the log file and returns the writer of it.
+func setupLogger() (io.Writer, error) {
+	logDir := filepath.Dir(env.BLOG_LOG_PATH)
+	if _, err := os.Stat(logDir); os.IsNotExist(err) {
+		err := os.MkdirAll(logDir, 0755)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var w io.Writer
+	if file, err := os.OpenFile(env.BLOG_LOG_PATH, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+		w = file
+	} else {
+		w = os.Stderr
+	}
+
+	logger := log.NewWithOptions(os.Stderr, log.Options{
+		ReportCaller:    true,
+		ReportTimestamp: true,
+		TimeFormat:      time.Kitchen,
+		Level:           log.DebugLevel,
+		Formatter: func() log.Formatter {
+			return log.TextFormatter
+		}(),
+	})
+	logger.SetOutput(w)
+	logger.With("run_id", runID())
+	slog.SetDefault(slog.New(logger))
+
+	slog.Debug("root command started",
+		"version", version,
+		"revision", revision,
+		"go", runtime.Version(),
+		"args", os.Args,
+	)
+	return w, nil
+}
+
+// readConfig reads the config with the overrides of the environment and
+// the flags applied, and selects the site.
+func readConfig() (config.Config, error) {
+	c, err := config.Read(configPath)
+	if err != nil {
+		return c, err
+	}
+	c, err = c.Override(os.LookupEnv, sets)
+	if err != nil {
+		return c, err
+	}
+	return c.WithSite(siteName)
+}
+
+// validConfig is the annotation of the commands working on the articles,
+// which refuse to run unless the config is valid. The others, e.g. logs and
+// server, run with an invalid config to help fix it.
+const validConfig = "valid-config"
+
+// loadConfig puts the config into the context of the command, which is
+// validated if the command needs it.
+func loadConfig(cmd *cobra.Command, w io.Writer) error {
+	c, err := readConfig()
+	if err != nil {
+		return err
+	}
+	if cmd.Annotations[validConfig] != "" {
+		if err := config.Validate(c); err != nil {
+			return fmt.Errorf("invalid config (see \"blog config validate\"):\n%w", err)
+		}
+	}
+	c.LogWriter = w
+
+	ctx := context.WithValue(cmd.Context(), config.Key, c)
+	cmd.SetContext(ctx)
+	return nil
+}
+
 func Execute() error {
 	return newRootCmd().Execute()
 }
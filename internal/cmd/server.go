@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/babarot/blog/internal/config"
+	"github.com/babarot/blog/internal/env"
+	"github.com/babarot/blog/internal/hugo"
+	"github.com/spf13/cobra"
+)
+
+type serverCmd struct {
+	config config.Config
+
+	all bool
+}
+
+func newServerCmd() *cobra.Command {
+	c := &serverCmd{}
+
+	serverCmd := &cobra.Command{
+		Use:                   "server",
+		Short:                 "Manage the hugo server shared by blog commands",
+		Aliases:               []string{},
+		GroupID:               "sub",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:                   "status",
+		Short:                 "Show the running hugo servers",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := cmd.Context().Value(config.Key).(config.Config)
+			c.config = cfg
+			return c.status()
+		},
+	}
+
+	stopCmd := &cobra.Command{
+		Use:                   "stop",
+		Short:                 "Stop the hugo server of the site",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := cmd.Context().Value(config.Key).(config.Config)
+			c.config = cfg
+			return c.stop()
+		},
+	}
+	stopCmd.Flags().BoolVarP(&c.all, "all", "a", false, "stop the servers of all sites")
+
+	serverCmd.AddCommand(statusCmd, stopCmd)
+	return serverCmd
+}
+
+func (c *serverCmd) status() error {
+	instances, err := hugo.Instances(env.BLOG_STATE_DIR)
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		fmt.Println("no hugo server is running")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIR\tPID\tPORT\tSTATUS\tCLIENTS\tUPTIME")
+	for _, inst := range instances {
+		status := "running"
+		if !inst.Alive() {
+			status = "dead"
+		}
+		var clients []string
+		for _, pid := range inst.Clients {
+			clients = append(clients, strconv.Itoa(pid))
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\n",
+			inst.Dir,
+			inst.PID,
+			inst.Port,
+			status,
+			strings.Join(clients, ","),
+			time.Since(inst.StartedAt).Round(time.Second),
+		)
+	}
+	return tw.Flush()
+}
+
+func (c *serverCmd) stop() error {
+	if !c.all {
+		return hugo.Stop(env.BLOG_STATE_DIR, c.config.Hugo.RootDir)
+	}
+	instances, err := hugo.Instances(env.BLOG_STATE_DIR)
+	if err != nil {
+		return err
+	}
+	for _, inst := range instances {
+		if err := hugo.Stop(env.BLOG_STATE_DIR, inst.Dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
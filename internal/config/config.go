@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,6 +13,7 @@ import (
 	"github.com/MakeNowJust/heredoc"
 	"github.com/babarot/blog/internal/env"
 	"github.com/babarot/blog/internal/shell"
+	"github.com/babarot/blog/internal/slug"
 	"github.com/go-playground/validator"
 	"github.com/muesli/reflow/indent"
 	"gopkg.in/yaml.v2"
@@ -24,10 +26,27 @@ const Key KeyType = "config"
 type Config struct {
 	LogWriter io.Writer `yaml:"-"`
 
-	Blog   Blog   `yaml:"blog"`
-	Hugo   Hugo   `yaml:"hugo"`
-	Editor string `yaml:"editor"`
-	Open   string `yaml:"open_command"`
+	Blog      Blog              `yaml:"blog"`
+	Hugo      Hugo              `yaml:"hugo"`
+	Editor    string            `yaml:"editor"`
+	EditorEnv map[string]string `yaml:"editor_env,omitempty"`
+	Open      string            `yaml:"open_command"`
+	Slug      SlugConfig        `yaml:"slug"`
+
+	// the sites are validated one by one when they are selected
+	Sites       []Site `yaml:"sites,omitempty" validate:"-"`
+	DefaultSite string `yaml:"default_site,omitempty"`
+	// SiteName is the name of the site selected by WithSite
+	SiteName string `yaml:"-"`
+	// Path is the global config file read, empty if there is none
+	Path string `yaml:"-"`
+	// ProjectDir is the hugo root found from the working directory, and
+	// ProjectFile is the project config file in it if any
+	ProjectDir  string `yaml:"-"`
+	ProjectFile string `yaml:"-"`
+
+	overrides []override
+	origins   origins
 }
 
 var validate *validator.Validate
@@ -43,28 +62,61 @@ type parser struct{}
 
 type Blog struct {
 	Name    string      `yaml:"name"`
-	URL     string      `yaml:"url"`
-	DevPort int         `yaml:"dev_port"`
+	URL     string      `yaml:"url" validate:"required,url"`
+	DevPort int         `yaml:"dev_port" validate:"min=0,max=65535"`
 	Draft   DraftConfig `yaml:"draft"`
+
+	// Permalink is the path of the articles with the tokens of hugo such
+	// as :year and :slug, or "hugo" to take the permalinks of the site.
+	Permalink string `yaml:"permalink" validate:"omitempty,permalink"`
 }
 
+// DefaultPermalink is used when blog.permalink is not set.
+const DefaultPermalink = "/post/:year/:month/:day/:slugorcontentbasename/"
+
+// DefaultNewPath is used when hugo.new_path is not set.
+const DefaultNewPath = "{{.Dir}}/{{.Year}}/{{.Slug}}/index.md"
+
+// HugoPermalink is the blog.permalink to follow the hugo config.
+const HugoPermalink = "hugo"
+
 type DraftConfig struct {
 	Suffix string `yaml:"suffix"`
-	Color  string `yaml:"color"`
+	Color  string `yaml:"color" validate:"omitempty,color"`
+
+	// UpdateDateOnPublish sets date and lastmod to now
+	// when a draft is published from the UI.
+	UpdateDateOnPublish bool `yaml:"update_date_on_publish"`
+}
+
+// SlugConfig is how the slug of a new article is made from its title.
+type SlugConfig struct {
+	// Dictionary replaces the words of the title, e.g. 日記: diary
+	Dictionary map[string]string `yaml:"dictionary,omitempty"`
+	// Fallback is the date layout of the slug when the title gives nothing
+	Fallback string `yaml:"fallback"`
 }
 
 type Hugo struct {
-	Command    string `yaml:"command"`
-	RootDir    string `yaml:"root_dir"`
+	Command    string `yaml:"command" validate:"required,template"`
+	RootDir    string `yaml:"root_dir" validate:"required,dir"`
 	ContentDir string `yaml:"content_dir"`
+
+	// NewPath is the template of the path of a new article in the
+	// content directory of hugo, e.g. "{{.Section}}/{{.Year}}/{{.Slug}}/index.md"
+	NewPath string `yaml:"new_path" validate:"omitempty,template"`
+
+	// Env is set to hugo, e.g. HUGO_ENV and HUGO_PARAMS_*
+	Env map[string]string `yaml:"env,omitempty"`
 }
 
-func (p parser) getDefaultConfig() Config {
+// defaults returns the values taken when they are left out of the config
+// files, which are layered over them.
+func defaults() Config {
 	return Config{
 		Blog: Blog{
-			Name:    "My site",
-			URL:     "https://example.com",
-			DevPort: 1313,
+			DevPort:   1313,
+			Permalink: DefaultPermalink,
 			Draft: DraftConfig{
 				Suffix: "::Draft",
 				Color:  "#5FB458",
@@ -72,12 +124,34 @@ func (p parser) getDefaultConfig() Config {
 		},
 		Hugo: Hugo{
 			Command: "hugo server",
+			NewPath: DefaultNewPath,
 		},
 		Editor: "vim",
 		Open:   "open",
+		Slug: SlugConfig{
+			Fallback: slug.DefaultFallback,
+		},
 	}
 }
 
+// fillDefaults sets the defaults to the values left empty, for which the
+// commands would take them anyway, so that the config shows what is used.
+func (c *Config) fillDefaults() {
+	if c.Blog.Permalink == "" {
+		c.Blog.Permalink = DefaultPermalink
+	}
+	if c.Hugo.NewPath == "" {
+		c.Hugo.NewPath = DefaultNewPath
+	}
+}
+
+func (p parser) getDefaultConfig() Config {
+	cfg := defaults()
+	cfg.Blog.Name = "My site"
+	cfg.Blog.URL = "https://example.com"
+	return cfg
+}
+
 func (p parser) getDefaultConfigContents() string {
 	defaultConfig := p.getDefaultConfig()
 	content, _ := yaml.Marshal(defaultConfig)
@@ -93,6 +167,7 @@ func (e configError) Error() string {
 		---
 		%s
 		---
+		Or run "blog config init" to create it interactively.
 		Original error:
 		%s
 		`,
@@ -171,7 +246,7 @@ func (e parsingError) Error() string {
 }
 
 func (p parser) readConfigFile(path string) (Config, error) {
-	var cfg Config
+	cfg := defaults()
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return cfg, configError{
@@ -185,15 +260,20 @@ func (p parser) readConfigFile(path string) (Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return cfg, err
 	}
-
-	if err := validate.Struct(cfg); err != nil {
-		for _, err := range err.(validator.ValidationErrors) {
-			return cfg, fmt.Errorf("validation error: Field %s, %q is invalid\n", err.Field(), err.Value())
-		}
+	cfg.recordOrigins(path, data, "")
+	cfg.Sites, err = parseSites(data, cfg)
+	if err != nil {
+		return cfg, err
 	}
+	cfg.inheritOrigins()
 	return cfg, nil
 }
 
+// Default returns the config used when there is no config file.
+func Default() Config {
+	return parser{}.getDefaultConfig()
+}
+
 func initParser() parser {
 	validate = validator.New()
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -203,11 +283,14 @@ func initParser() parser {
 		}
 		return name
 	})
+	registerValidations(validate)
 
 	return parser{}
 }
 
-func Parse(path string) (Config, error) {
+// Read reads the global config file at path and the project config
+// found from the working directory without validating them.
+func Read(path string) (Config, error) {
 	parser := initParser()
 
 	var cfg Config
@@ -224,22 +307,54 @@ func Parse(path string) (Config, error) {
 	}
 	slog.Debug("config file found", "config-file", configPath)
 
-	cfg, err = parser.readConfigFile(configPath)
+	wd, err := os.Getwd()
 	if err != nil {
 		return cfg, parsingError{err: err}
 	}
+	proj, hasProject := findProject(wd)
 
-	rootDir, err := shell.ExpandHome(cfg.Hugo.RootDir)
+	cfg, err = parser.readConfigFile(configPath)
 	if err != nil {
-		return cfg, parsingError{err: err}
+		// the project config is enough to work on the project
+		var cerr configError
+		if !hasProject || !errors.As(err, &cerr) {
+			return cfg, parsingError{err: err}
+		}
+		slog.Debug("config file not found, use the defaults", "config-file", configPath)
+		cfg = parser.getDefaultConfig()
+	} else {
+		cfg.Path = configPath
+	}
+	if hasProject {
+		cfg, err = applyProject(cfg, proj)
+		if err != nil {
+			return cfg, parsingError{err: err}
+		}
 	}
-	cfg.Hugo.RootDir = rootDir
 
-	contentDir, err := shell.ExpandHome(cfg.Hugo.ContentDir)
-	if err != nil {
+	if err := expandDirs(&cfg.Hugo); err != nil {
 		return cfg, parsingError{err: err}
 	}
-	cfg.Hugo.ContentDir = contentDir
+	for i := range cfg.Sites {
+		if err := expandDirs(&cfg.Sites[i].Hugo); err != nil {
+			return cfg, parsingError{err: err}
+		}
+	}
 
 	return cfg, nil
 }
+
+func expandDirs(h *Hugo) error {
+	rootDir, err := shell.ExpandHome(h.RootDir)
+	if err != nil {
+		return err
+	}
+	h.RootDir = rootDir
+
+	contentDir, err := shell.ExpandHome(h.ContentDir)
+	if err != nil {
+		return err
+	}
+	h.ContentDir = contentDir
+	return nil
+}
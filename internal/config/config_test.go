@@ -0,0 +1,66 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/babarot/blog/internal/slug"
+)
+
+func TestReadConfigFileDefaults(t *testing.T) {
+	cfg := readFile(t, t.TempDir(), `blog:
+  url: https://example.com
+  draft:
+    suffix: " (draft)"
+hugo:
+  root_dir: /srv/blog
+sites:
+  - name: tech
+    hugo:
+      root_dir: /srv/tech
+`)
+	tests := []struct {
+		key  string
+		got  any
+		want any
+	}{
+		{key: "blog.permalink", got: cfg.Blog.Permalink, want: DefaultPermalink},
+		{key: "blog.dev_port", got: cfg.Blog.DevPort, want: 1313},
+		{key: "blog.draft.suffix", got: cfg.Blog.Draft.Suffix, want: " (draft)"},
+		{key: "blog.draft.color", got: cfg.Blog.Draft.Color, want: "#5FB458"},
+		{key: "hugo.command", got: cfg.Hugo.Command, want: "hugo server"},
+		{key: "hugo.new_path", got: cfg.Hugo.NewPath, want: DefaultNewPath},
+		{key: "slug.fallback", got: cfg.Slug.Fallback, want: slug.DefaultFallback},
+		{key: "sites[0].hugo.new_path", got: cfg.Sites[0].Hugo.NewPath, want: DefaultNewPath},
+		{key: "sites[0].blog.dev_port", got: cfg.Sites[0].Blog.DevPort, want: 1313},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverrideDefaults(t *testing.T) {
+	cfg := readFile(t, t.TempDir(), `blog:
+  url: https://example.com
+  permalink: ""
+hugo:
+  root_dir: /srv/blog
+  new_path: ""
+`)
+	cfg, err := cfg.Override(func(string) (string, bool) { return "", false }, []string{"blog.dev_port=1414"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Blog.Permalink != DefaultPermalink {
+		t.Errorf("blog.permalink = %q, want %q", cfg.Blog.Permalink, DefaultPermalink)
+	}
+	if cfg.Hugo.NewPath != DefaultNewPath {
+		t.Errorf("hugo.new_path = %q, want %q", cfg.Hugo.NewPath, DefaultNewPath)
+	}
+	if cfg.Blog.DevPort != 1414 {
+		t.Errorf("blog.dev_port = %d, want %d", cfg.Blog.DevPort, 1414)
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// origin is the line of the config file where a value is written.
+type origin struct {
+	file string
+	line int
+}
+
+// origins maps the keys such as "blog.url" and "sites[0].hugo.root_dir" to
+// where their values come from. The keys of the defaults, and of the values
+// worked out from the directories, are not in it.
+type origins map[string]origin
+
+// recordOrigins takes the keys written in the YAML data of the file as the
+// origins of their values. With the prefix such as "sites[0].", only the
+// blog and hugo settings are taken for the ones of the site.
+func (c *Config) recordOrigins(file string, data []byte, prefix string) {
+	var doc yaml3.Node
+	if yaml3.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 {
+		return
+	}
+	if c.origins == nil {
+		c.origins = origins{}
+	}
+	walkNode(doc.Content[0], "", func(key string, line int) {
+		if prefix != "" && !strings.HasPrefix(key, "blog.") && !strings.HasPrefix(key, "hugo.") {
+			return
+		}
+		c.origins[prefix+key] = origin{file: file, line: line}
+	})
+}
+
+// walkNode calls fn with the keys under the node and their lines.
+func walkNode(node *yaml3.Node, key string, fn func(string, int)) {
+	switch node.Kind {
+	case yaml3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i].Value
+			if key != "" {
+				k = key + "." + k
+			}
+			fn(k, node.Content[i].Line)
+			walkNode(node.Content[i+1], k, fn)
+		}
+	case yaml3.SequenceNode:
+		for i, item := range node.Content {
+			k := fmt.Sprintf("%s[%d]", key, i)
+			fn(k, item.Line)
+			walkNode(item, k, fn)
+		}
+	}
+}
+
+// inheritOrigins gives the sites the origins of the top-level settings
+// they take since they are left out of their entries.
+func (c *Config) inheritOrigins() {
+	var keys []string
+	for key := range c.origins {
+		if strings.HasPrefix(key, "blog.") || strings.HasPrefix(key, "hugo.") {
+			keys = append(keys, key)
+		}
+	}
+	for i := range c.Sites {
+		prefix := fmt.Sprintf("sites[%d].", i)
+		for _, key := range keys {
+			if _, ok := c.origins[prefix+key]; !ok {
+				c.origins[prefix+key] = c.origins[key]
+			}
+		}
+	}
+}
+
+// dropOrigins forgets the origins of the keys and the ones under them,
+// whose values no longer come from the files.
+func (c *Config) dropOrigins(keys ...string) {
+	for k := range c.origins {
+		for _, key := range keys {
+			if k == key || strings.HasPrefix(k, key+".") || strings.HasPrefix(k, key+"[") {
+				delete(c.origins, k)
+			}
+		}
+	}
+}
+
+// origin returns where the value of the key of the selected site comes from.
+func (c Config) origin(key string) (origin, bool) {
+	for i, site := range c.Sites {
+		if c.SiteName != "" && site.Name == c.SiteName {
+			key = fmt.Sprintf("sites[%d].%s", i, key)
+			break
+		}
+	}
+	o, ok := c.origins[key]
+	return o, ok
+}
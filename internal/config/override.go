@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is prepended to the keys to override them by the environment
+// variables, e.g. BLOG_HUGO_ROOT_DIR for hugo.root_dir. The keys of the
+// blog section go without it not to repeat the prefix, e.g. BLOG_URL for
+// blog.url.
+const EnvPrefix = "BLOG_"
+
+// override is a value given by the environment or the --set flag.
+type override struct {
+	key    string
+	value  string
+	source string
+}
+
+// Keys returns the keys of the config fields that can be overridden,
+// such as "blog.url" and "hugo.root_dir". The maps are left out since
+// their entries are set with --set only, e.g. "hugo.env.HUGO_ENV=staging".
+func Keys() []string {
+	var keys []string
+	walkFields(reflect.TypeOf(Config{}), "", func(key string, t reflect.Type) {
+		if t.Kind() != reflect.Map {
+			keys = append(keys, key)
+		}
+	})
+	return keys
+}
+
+// EnvName returns the name of the environment variable for the key.
+func EnvName(key string) string {
+	key = strings.TrimPrefix(key, "blog.")
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func walkFields(t reflect.Type, prefix string, fn func(string, reflect.Type)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			walkFields(f.Type, key+".", fn)
+		case reflect.String, reflect.Int, reflect.Bool, reflect.Map:
+			fn(key, f.Type)
+		}
+	}
+}
+
+// Override sets the values of the BLOG_* environment variables looked up
+// by lookupEnv and then the key=value pairs given by the --set flag, so
+// that the flags win over the environment, which wins over the files.
+func (c Config) Override(lookupEnv func(string) (string, bool), sets []string) (Config, error) {
+	var overrides []override
+	for _, key := range Keys() {
+		if value, ok := lookupEnv(EnvName(key)); ok {
+			overrides = append(overrides, override{key: key, value: value, source: EnvName(key)})
+		}
+	}
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return c, fmt.Errorf("invalid --set %q: must be key=value", set)
+		}
+		overrides = append(overrides, override{key: strings.TrimSpace(key), value: value, source: "--set"})
+	}
+	c.overrides = append(slices.Clone(c.overrides), overrides...)
+	return c, c.applyOverrides()
+}
+
+// overriddenBy returns the source of the value of the key if it has been
+// overridden by the environment or the flags.
+func (c Config) overriddenBy(key string) string {
+	var source string
+	for _, o := range c.overrides {
+		if o.key == key {
+			source = o.source
+		}
+	}
+	return source
+}
+
+func (c *Config) applyOverrides() error {
+	for _, o := range c.overrides {
+		if err := c.Set(o.key, o.value); err != nil {
+			return fmt.Errorf("%s: %w", o.source, err)
+		}
+	}
+	c.fillDefaults()
+	return expandDirs(&c.Hugo)
+}
+
+// Set sets the value to the field of the key. A key of a map field
+// takes the name of the entry, e.g. "hugo.env.HUGO_ENV".
+func (c *Config) Set(key, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		field, ok := fieldByTag(v, part)
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		switch field.Kind() {
+		case reflect.Struct:
+			v = field
+			continue
+		case reflect.Map:
+			if i != len(parts)-2 {
+				return fmt.Errorf("config key %q must be %s.NAME", key, strings.Join(parts[:i+1], "."))
+			}
+			if field.IsNil() {
+				field.Set(reflect.MakeMap(field.Type()))
+			} else {
+				// not to modify the map shared with the other sites
+				clone := reflect.MakeMap(field.Type())
+				iter := field.MapRange()
+				for iter.Next() {
+					clone.SetMapIndex(iter.Key(), iter.Value())
+				}
+				field.Set(clone)
+			}
+			field.SetMapIndex(reflect.ValueOf(parts[i+1]), reflect.ValueOf(value))
+			return nil
+		}
+		if i != len(parts)-1 {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		return setValue(field, key, value)
+	}
+	return fmt.Errorf("config key %q is not a value", key)
+}
+
+func fieldByTag(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if tag == name && tag != "-" {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func setValue(field reflect.Value, key, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("config key %q must be a number: %q", key, value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("config key %q must be true or false: %q", key, value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("config key %q cannot be set", key)
+	}
+	return nil
+}
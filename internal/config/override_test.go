@@ -0,0 +1,38 @@
+package config
+
+import "testing"
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "blog.url", want: "BLOG_URL"},
+		{key: "blog.draft.suffix", want: "BLOG_DRAFT_SUFFIX"},
+		{key: "hugo.root_dir", want: "BLOG_HUGO_ROOT_DIR"},
+		{key: "editor", want: "BLOG_EDITOR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := EnvName(tt.key); got != tt.want {
+				t.Errorf("EnvName(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvNameUnique(t *testing.T) {
+	// the variables of the paths are read before the config
+	seen := map[string]string{
+		"BLOG_CONFIG_PATH": "",
+		"BLOG_LOG_PATH":    "",
+		"BLOG_STATE_DIR":   "",
+	}
+	for _, key := range Keys() {
+		name := EnvName(key)
+		if other, ok := seen[name]; ok {
+			t.Errorf("%s is taken by both %q and %q", name, key, other)
+		}
+		seen[name] = key
+	}
+}
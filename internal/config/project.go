@@ -0,0 +1,278 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/babarot/blog/internal/shell"
+	"gopkg.in/yaml.v2"
+)
+
+// ProjectFile is the name of the project config written by "blog config init".
+const ProjectFile = ".blog.yaml"
+
+// project is the hugo site found around the working directory.
+type project struct {
+	dir string
+	// configPath is empty when only the hugo config is found
+	configPath string
+}
+
+// findProject walks up from dir looking for the project config file, or
+// for a hugo site whose root_dir can be inferred. It does not go beyond
+// the limit of the walk, so a stray file higher up is not taken.
+func findProject(dir string) (project, bool) {
+	last := walkLimit(dir)
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if isFile(path) {
+			return project{dir: dir, configPath: path}, true
+		}
+		if isHugoRoot(dir) {
+			return project{dir: dir}, true
+		}
+		parent := filepath.Dir(dir)
+		if dir == last || parent == dir {
+			return project{}, false
+		}
+		dir = parent
+	}
+}
+
+// vcsDirs mark the root of a repository.
+var vcsDirs = []string{".git", ".hg"}
+
+// walkLimit returns the last directory findProject looks at from dir: the
+// root of the repository holding it, or else the one right below the home
+// directory, or else dir itself.
+func walkLimit(dir string) string {
+	home, _ := os.UserHomeDir()
+	for d := dir; d != home; {
+		for _, name := range vcsDirs {
+			if _, err := os.Stat(filepath.Join(d, name)); err == nil {
+				return d
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == home {
+			return d
+		}
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	return dir
+}
+
+// FindProject returns the hugo root found from dir and the project
+// config file in it, which is empty if there is none.
+func FindProject(dir string) (root, file string, ok bool) {
+	proj, ok := findProject(dir)
+	return proj.dir, proj.configPath, ok
+}
+
+// HugoSite is the part of the hugo config used to fill in the blog config.
+type HugoSite struct {
+	Title      string `toml:"title" yaml:"title" json:"title"`
+	BaseURL    string `toml:"baseURL" yaml:"baseURL" json:"baseURL"`
+	ContentDir string `toml:"contentDir" yaml:"contentDir" json:"contentDir"`
+
+	// Permalinks maps the sections to their patterns, or the kinds such
+	// as "page" to the maps of them.
+	Permalinks map[string]any `toml:"permalinks" yaml:"permalinks" json:"permalinks"`
+}
+
+// ReadHugoSite reads the hugo config in the root directory.
+func ReadHugoSite(root string) (HugoSite, error) {
+	var site HugoSite
+	for _, name := range []string{"hugo", "config"} {
+		for _, ext := range []string{"toml", "yaml", "yml", "json"} {
+			path := filepath.Join(root, name+"."+ext)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			switch ext {
+			case "toml":
+				err = toml.Unmarshal(data, &site)
+			case "json":
+				err = json.Unmarshal(data, &site)
+			default:
+				err = yaml.Unmarshal(data, &site)
+			}
+			if err != nil {
+				return site, fmt.Errorf("%s: %w", path, err)
+			}
+			return site, nil
+		}
+	}
+	return site, os.ErrNotExist
+}
+
+// ContentRoot returns the content directory of hugo, which is contentDir
+// of the hugo config under the root directory (default: content).
+func (h Hugo) ContentRoot() string {
+	dir := "content"
+	if site, err := ReadHugoSite(h.RootDir); err == nil && site.ContentDir != "" {
+		dir = filepath.FromSlash(site.ContentDir)
+	}
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(h.RootDir, dir)
+}
+
+// ContentPath returns content_dir relative to the content root of hugo in
+// slash form, e.g. post of content/post. It is empty when content_dir is
+// the content root or out of it.
+func (h Hugo) ContentPath() string {
+	rel, err := filepath.Rel(h.ContentRoot(), filepath.Join(h.RootDir, h.ContentDir))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+func isHugoRoot(dir string) bool {
+	for _, ext := range []string{"toml", "yaml", "yml", "json"} {
+		if isFile(filepath.Join(dir, "hugo."+ext)) {
+			return true
+		}
+		// config.* is too common a name to be sure without the content
+		if isFile(filepath.Join(dir, "config."+ext)) && isDir(filepath.Join(dir, "content")) {
+			return true
+		}
+	}
+	return false
+}
+
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// applyProject layers the project settings over the global config.
+// The root_dir of the project is its directory unless it is set in the
+// project config, where a relative one is taken from that directory.
+func applyProject(cfg Config, proj project) (Config, error) {
+	cfg.ProjectDir = proj.dir
+	cfg.ProjectFile = proj.configPath
+	// not to change the origins of the global config
+	cfg.origins = maps.Clone(cfg.origins)
+
+	var local struct {
+		Hugo struct {
+			RootDir string `yaml:"root_dir"`
+		} `yaml:"hugo"`
+		Sites       []yaml.MapSlice `yaml:"sites"`
+		DefaultSite string          `yaml:"default_site"`
+	}
+	var data []byte
+	if proj.configPath != "" {
+		var err error
+		data, err = os.ReadFile(proj.configPath)
+		if err != nil {
+			return cfg, err
+		}
+		if err := yaml.Unmarshal(data, &local); err != nil {
+			return cfg, fmt.Errorf("%s: %w", proj.configPath, err)
+		}
+		if len(local.Sites) > 0 {
+			if err := yaml.Unmarshal(data, &cfg); err != nil {
+				return cfg, fmt.Errorf("%s: %w", proj.configPath, err)
+			}
+			cfg.dropOrigins("sites")
+			cfg.recordOrigins(proj.configPath, data, "")
+			cfg.Sites, err = parseSites(data, cfg)
+			if err != nil {
+				return cfg, fmt.Errorf("%s: %w", proj.configPath, err)
+			}
+			// the project decides its sites by itself
+			cfg.inheritOrigins()
+			return cfg, nil
+		}
+	}
+
+	rootDir, err := shell.ExpandHome(local.Hugo.RootDir)
+	if err != nil {
+		return cfg, err
+	}
+	switch {
+	case rootDir == "":
+		rootDir = proj.dir
+	case !filepath.IsAbs(rootDir):
+		rootDir = filepath.Join(proj.dir, rootDir)
+	}
+
+	// use the global settings of the site if it is in the sites list,
+	// otherwise the top-level ones with this root_dir
+	site := -1
+	if local.DefaultSite == "" {
+		cfg.DefaultSite = ""
+		for i, s := range cfg.Sites {
+			if sameDir(s.Hugo.RootDir, rootDir) {
+				site = i
+				break
+			}
+		}
+		if site < 0 && cfg.Hugo.RootDir != "" && !sameDir(cfg.Hugo.RootDir, rootDir) {
+			// the top-level settings are of another site
+			cfg.Blog.Name, cfg.Blog.URL, cfg.Hugo.ContentDir = "", "", ""
+			cfg.dropOrigins("blog.name", "blog.url", "hugo.content_dir")
+			if hugo, err := ReadHugoSite(rootDir); err == nil {
+				cfg.Blog.Name, cfg.Blog.URL, cfg.Hugo.ContentDir = hugo.Title, hugo.BaseURL, hugo.ContentDir
+			}
+		}
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", proj.configPath, err)
+	}
+	cfg.recordOrigins(proj.configPath, data, "")
+	cfg.Hugo.RootDir = rootDir
+	if local.Hugo.RootDir == "" {
+		cfg.dropOrigins("hugo.root_dir")
+	}
+	if cfg.Hugo.ContentDir == "" {
+		cfg.Hugo.ContentDir = "content"
+	}
+
+	if site >= 0 {
+		s := &cfg.Sites[site]
+		cfg.DefaultSite = s.Name
+		// the project settings win over the ones of the site too
+		overlay := struct {
+			Blog *Blog `yaml:"blog"`
+			Hugo *Hugo `yaml:"hugo"`
+		}{&s.Blog, &s.Hugo}
+		if err := yaml.Unmarshal(data, &overlay); err != nil {
+			return cfg, fmt.Errorf("%s: %w", proj.configPath, err)
+		}
+		s.Hugo.RootDir = rootDir
+		prefix := fmt.Sprintf("sites[%d].", site)
+		cfg.recordOrigins(proj.configPath, data, prefix)
+		if local.Hugo.RootDir == "" {
+			cfg.dropOrigins(prefix + "hugo.root_dir")
+		}
+	}
+	slog.Debug("project found", "dir", proj.dir, "config-file", proj.configPath, "site", cfg.DefaultSite)
+	return cfg, nil
+}
+
+// sameDir reports whether the root_dir in the config is dir.
+func sameDir(rootDir, dir string) bool {
+	rootDir, err := shell.ExpandHome(rootDir)
+	return err == nil && filepath.Clean(rootDir) == filepath.Clean(dir)
+}
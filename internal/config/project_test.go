@@ -0,0 +1,213 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes the files, of which the names ending with / are
+// directories, under root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindProject(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		dir   string
+		want  string
+		ok    bool
+	}{
+		{
+			name:  "project file in a parent",
+			files: map[string]string{"home/site/.blog.yaml": "", "home/site/content/post/": ""},
+			dir:   "home/site/content/post",
+			want:  "home/site",
+			ok:    true,
+		},
+		{
+			name:  "hugo config in a parent",
+			files: map[string]string{"home/site/hugo.toml": "", "home/site/content/post/": ""},
+			dir:   "home/site/content/post",
+			want:  "home/site",
+			ok:    true,
+		},
+		{
+			name:  "config.toml without content",
+			files: map[string]string{"home/work/config.toml": "", "home/work/src/": ""},
+			dir:   "home/work/src",
+		},
+		{
+			name:  "generic name",
+			files: map[string]string{"home/site/blog.yaml": "", "home/site/content/": ""},
+			dir:   "home/site/content",
+		},
+		{
+			name:  "project file above the repository",
+			files: map[string]string{"home/work/.blog.yaml": "", "home/work/repo/.git/": "", "home/work/repo/src/": ""},
+			dir:   "home/work/repo/src",
+		},
+		{
+			name:  "project file at the root of the repository",
+			files: map[string]string{"home/work/repo/.blog.yaml": "", "home/work/repo/.git/": "", "home/work/repo/src/": ""},
+			dir:   "home/work/repo/src",
+			want:  "home/work/repo",
+			ok:    true,
+		},
+		{
+			name:  "project file in the home directory",
+			files: map[string]string{"home/.blog.yaml": "", "home/work/src/": ""},
+			dir:   "home/work/src",
+		},
+		{
+			name:  "project file above the directory out of home",
+			files: map[string]string{"tmp/.blog.yaml": "", "tmp/work/": ""},
+			dir:   "tmp/work",
+		},
+		{
+			name:  "project file in the directory out of home",
+			files: map[string]string{"tmp/work/.blog.yaml": ""},
+			dir:   "tmp/work",
+			want:  "tmp/work",
+			ok:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Setenv("HOME", filepath.Join(root, "home"))
+			writeFiles(t, root, tt.files)
+			proj, ok := findProject(filepath.Join(root, filepath.FromSlash(tt.dir)))
+			if ok != tt.ok {
+				t.Fatalf("findProject() found %q, want %v", proj.dir, tt.ok)
+			}
+			if want := filepath.Join(root, filepath.FromSlash(tt.want)); ok && proj.dir != want {
+				t.Errorf("findProject() = %q, want %q", proj.dir, want)
+			}
+		})
+	}
+}
+
+func TestApplyProject(t *testing.T) {
+	global := Config{
+		Blog: Blog{Name: "Other", URL: "https://other.example.com"},
+		Hugo: Hugo{RootDir: "/srv/other", ContentDir: "content/post"},
+	}
+	tests := []struct {
+		name   string
+		global Config
+		files  map[string]string
+		want   Config
+	}{
+		{
+			name:   "project file",
+			global: global,
+			files: map[string]string{
+				".blog.yaml": "blog:\n  name: Tech\n  url: https://tech.example.com\nhugo:\n  content_dir: content/tech\n",
+			},
+			want: Config{
+				Blog: Blog{Name: "Tech", URL: "https://tech.example.com"},
+				Hugo: Hugo{ContentDir: "content/tech"},
+			},
+		},
+		{
+			name:   "relative root_dir",
+			global: global,
+			files: map[string]string{
+				".blog.yaml": "blog:\n  url: https://tech.example.com\nhugo:\n  root_dir: site\n",
+			},
+			want: Config{
+				Blog: Blog{URL: "https://tech.example.com"},
+				Hugo: Hugo{RootDir: "site", ContentDir: "content"},
+			},
+		},
+		{
+			name:   "settings of another site",
+			global: global,
+			files:  map[string]string{"hugo.toml": "title = \"Tech\"\nbaseURL = \"https://tech.example.com/\"\n"},
+			want: Config{
+				Blog: Blog{Name: "Tech", URL: "https://tech.example.com/"},
+				Hugo: Hugo{ContentDir: "content"},
+			},
+		},
+		{
+			name:   "settings of another site without the hugo values",
+			global: global,
+			files:  map[string]string{"hugo.toml": ""},
+			want:   Config{Hugo: Hugo{ContentDir: "content"}},
+		},
+		{
+			name:   "settings without root_dir",
+			global: Config{Blog: Blog{Name: "Mine", URL: "https://example.com"}},
+			files:  map[string]string{"hugo.toml": "title = \"Tech\"\n"},
+			want: Config{
+				Blog: Blog{Name: "Mine", URL: "https://example.com"},
+				Hugo: Hugo{ContentDir: "content"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			proj := project{dir: dir}
+			if _, ok := tt.files[ProjectFile]; ok {
+				proj.configPath = filepath.Join(dir, ProjectFile)
+			}
+			got, err := applyProject(tt.global, proj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.want
+			want.Hugo.RootDir = filepath.Join(dir, want.Hugo.RootDir)
+			if got.Blog != want.Blog || got.Hugo.RootDir != want.Hugo.RootDir || got.Hugo.ContentDir != want.Hugo.ContentDir {
+				t.Errorf("applyProject() = %+v %+v, want %+v %+v", got.Blog, got.Hugo, want.Blog, want.Hugo)
+			}
+		})
+	}
+}
+
+func TestApplyProjectSite(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{".blog.yaml": "blog:\n  name: Tech\n"})
+	cfg := Config{
+		Blog: Blog{Name: "Other", URL: "https://other.example.com"},
+		Hugo: Hugo{RootDir: "/srv/other", ContentDir: "content"},
+		Sites: []Site{
+			{Name: "other", Hugo: Hugo{RootDir: "/srv/other"}},
+			{Name: "tech", Blog: Blog{URL: "https://tech.example.com"}, Hugo: Hugo{RootDir: dir}},
+		},
+	}
+	got, err := applyProject(cfg, project{dir: dir, configPath: filepath.Join(dir, ProjectFile)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DefaultSite != "tech" {
+		t.Fatalf("DefaultSite = %q, want %q", got.DefaultSite, "tech")
+	}
+	site, err := got.WithSite("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if site.Blog.Name != "Tech" || site.Blog.URL != "https://tech.example.com" || site.Hugo.RootDir != dir {
+		t.Errorf("WithSite() = %q %q %q, want %q %q %q", site.Blog.Name, site.Blog.URL, site.Hugo.RootDir, "Tech", "https://tech.example.com", dir)
+	}
+}
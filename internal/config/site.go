@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Site is an entry of the sites list. The blog and hugo settings left out
+// are taken from the top-level ones.
+type Site struct {
+	Name string `yaml:"name"`
+	Blog Blog   `yaml:"blog"`
+	Hugo Hugo   `yaml:"hugo"`
+}
+
+// parseSites reads the sites over the top-level blog and hugo settings.
+func parseSites(data []byte, cfg Config) ([]Site, error) {
+	var raw struct {
+		Sites []yaml.MapSlice `yaml:"sites"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	sites := make([]Site, 0, len(raw.Sites))
+	for i, item := range raw.Sites {
+		site := Site{Blog: cfg.Blog, Hugo: cfg.Hugo}
+		// not to add the keys of the site to the top-level map
+		site.Hugo.Env = maps.Clone(cfg.Hugo.Env)
+		b, err := yaml.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(b, &site); err != nil {
+			return nil, fmt.Errorf("sites[%d]: %w", i, err)
+		}
+		if site.Name == "" {
+			return nil, fmt.Errorf("sites[%d]: name is required", i)
+		}
+		for _, s := range sites {
+			if s.Name == site.Name {
+				return nil, fmt.Errorf("sites[%d]: duplicate name %q", i, site.Name)
+			}
+		}
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
+
+// SiteNames returns the names of the sites in the order of the config.
+func (c Config) SiteNames() []string {
+	names := make([]string, 0, len(c.Sites))
+	for _, site := range c.Sites {
+		names = append(names, site.Name)
+	}
+	return names
+}
+
+// WithSite returns the config whose Blog and Hugo are of the given site.
+// An empty name selects default_site, or the first site when there are
+// sites but no top-level hugo.root_dir.
+func (c Config) WithSite(name string) (Config, error) {
+	if name == "" {
+		name = c.DefaultSite
+	}
+	if name == "" && c.Hugo.RootDir == "" && len(c.Sites) > 0 {
+		name = c.Sites[0].Name
+	}
+	if name == "" {
+		return c, nil
+	}
+	for _, site := range c.Sites {
+		if site.Name == name {
+			c.Blog = site.Blog
+			c.Hugo = site.Hugo
+			c.SiteName = site.Name
+			// the environment and the flags win over the site too
+			return c, c.applyOverrides()
+		}
+	}
+	if len(c.Sites) == 0 {
+		return c, fmt.Errorf("site %q not found: no sites in the config", name)
+	}
+	return c, fmt.Errorf("site %q not found (available: %s)", name, strings.Join(c.SiteNames(), ", "))
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/go-playground/validator"
+)
+
+// FieldError is a problem of a config field. File and Line tell where
+// the value is written, or Source tells the environment variable or the
+// flag that set it. They are all empty for the defaults.
+type FieldError struct {
+	Key     string
+	Message string
+	File    string
+	Line    int
+	Source  string
+}
+
+func (e FieldError) Error() string {
+	switch {
+	case e.Source != "":
+		return fmt.Sprintf("%s: %s (set by %s)", e.Key, e.Message, e.Source)
+	case e.File != "":
+		return fmt.Sprintf("%s:%d: %s: %s", e.File, e.Line, e.Key, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+var colorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// isColor accepts the colors of lipgloss: hex ones and ANSI numbers.
+func isColor(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if colorPattern.MatchString(s) {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// isPermalink accepts "hugo" or a path.
+func isPermalink(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	return s == HugoPermalink || strings.HasPrefix(s, "/")
+}
+
+func isTemplate(fl validator.FieldLevel) bool {
+	_, err := template.New("").Parse(fl.Field().String())
+	return err == nil
+}
+
+// validateHugo checks the content_dir under the root_dir.
+func validateHugo(sl validator.StructLevel) {
+	h := sl.Current().Interface().(Hugo)
+	if h.RootDir == "" || h.ContentDir == "" || !isDir(h.RootDir) {
+		return
+	}
+	if !isDir(filepath.Join(h.RootDir, h.ContentDir)) {
+		sl.ReportError(h.ContentDir, "content_dir", "ContentDir", "contentdir", "")
+	}
+}
+
+func registerValidations(v *validator.Validate) {
+	v.RegisterValidation("color", isColor)
+	v.RegisterValidation("permalink", isPermalink)
+	v.RegisterValidation("template", isTemplate)
+	v.RegisterStructValidation(validateHugo, Hugo{})
+}
+
+// Validate returns all the problems of the config as FieldErrors
+// joined together.
+func Validate(cfg Config) error {
+	if validate == nil {
+		initParser()
+	}
+	err := validate.Struct(cfg)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+	var errs []error
+	for _, err := range verrs {
+		// drop the leading "Config."
+		_, key, _ := strings.Cut(err.Namespace(), ".")
+		fe := FieldError{Key: key, Message: message(err)}
+		if source := cfg.overriddenBy(key); source != "" {
+			fe.Source = source
+		} else if o, ok := cfg.origin(key); ok {
+			fe.File, fe.Line = o.file, o.line
+		}
+		errs = append(errs, fe)
+	}
+	return errors.Join(errs...)
+}
+
+func message(err validator.FieldError) string {
+	switch err.Tag() {
+	case "required":
+		return "is required"
+	case "dir":
+		return fmt.Sprintf("directory %q does not exist", err.Value())
+	case "contentdir":
+		return fmt.Sprintf("directory %q does not exist under root_dir", err.Value())
+	case "url":
+		return fmt.Sprintf("%q is not a valid URL, e.g. https://example.com", err.Value())
+	case "min", "max":
+		return fmt.Sprintf("%v is out of the range 0-65535", err.Value())
+	case "color":
+		return fmt.Sprintf("%q is not a valid color, e.g. #5FB458 or 0-255", err.Value())
+	case "template":
+		_, err := template.New("").Parse(err.Value().(string))
+		return fmt.Sprintf("is not a valid template: %v", err)
+	case "permalink":
+		return fmt.Sprintf("%q must be %q or a path, e.g. %s", err.Value(), HugoPermalink, DefaultPermalink)
+	}
+	return fmt.Sprintf("%v is invalid (%s)", err.Value(), err.Tag())
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFile writes the global config file in dir and reads it.
+func readFile(t *testing.T, dir, content string) Config {
+	t.Helper()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := parser{}.readConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Path = path
+	return cfg
+}
+
+// fieldErrors returns the FieldErrors of Validate by the keys.
+func fieldErrors(t *testing.T, cfg Config) map[string]FieldError {
+	t.Helper()
+	errs := map[string]FieldError{}
+	err := Validate(cfg)
+	if err == nil {
+		return errs
+	}
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var fe FieldError
+		if !errors.As(err, &fe) {
+			t.Fatalf("not a FieldError: %v", err)
+		}
+		errs[fe.Key] = fe
+	}
+	return errs
+}
+
+func TestOrigins(t *testing.T) {
+	dir := t.TempDir()
+	cfg := readFile(t, dir, `blog:
+  url: https://example.com
+  draft:
+    color: "#5FB458"
+hugo:
+  root_dir: /srv/blog
+sites:
+  - name: tech
+    blog:
+      url: https://tech.example.com
+  - name: notes
+`)
+	path := filepath.Join(dir, "config.yaml")
+	tests := []struct {
+		key  string
+		line int
+	}{
+		{key: "blog.url", line: 2},
+		{key: "blog.draft.color", line: 4},
+		{key: "hugo.root_dir", line: 6},
+		{key: "sites[0].blog.url", line: 10},
+		{key: "sites[0].hugo.root_dir", line: 6},
+		{key: "sites[1].blog.url", line: 2},
+		{key: "sites[1].blog.draft.color", line: 4},
+		{key: "blog.dev_port", line: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			o, ok := cfg.origins[tt.key]
+			if tt.line == 0 {
+				if ok {
+					t.Errorf("origin of %s = %+v, want none", tt.key, o)
+				}
+				return
+			}
+			if o.file != path || o.line != tt.line {
+				t.Errorf("origin of %s = %s:%d, want %s:%d", tt.key, o.file, o.line, path, tt.line)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	cfg := readFile(t, dir, `blog:
+  url: bad-url
+  dev_port: 70000
+  draft:
+    color: green
+hugo:
+  command: hugo server
+  root_dir: `+dir+`
+  content_dir: missing
+`)
+	path := filepath.Join(dir, "config.yaml")
+	cfg, err := cfg.Override(func(name string) (string, bool) {
+		if name == "BLOG_DEV_PORT" {
+			return "80000", true
+		}
+		return "", false
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]FieldError{
+		"blog.url":         {File: path, Line: 2},
+		"blog.dev_port":    {Source: "BLOG_DEV_PORT"},
+		"blog.draft.color": {File: path, Line: 5},
+		"hugo.content_dir": {File: path, Line: 9},
+	}
+	got := fieldErrors(t, cfg)
+	if len(got) != len(want) {
+		t.Errorf("Validate() = %v, want the errors of %d keys", got, len(want))
+	}
+	for key, w := range want {
+		g, ok := got[key]
+		if !ok {
+			t.Errorf("no error of %s", key)
+			continue
+		}
+		if g.File != w.File || g.Line != w.Line || g.Source != w.Source {
+			t.Errorf("error of %s is from %s:%d %q, want %s:%d %q", key, g.File, g.Line, g.Source, w.File, w.Line, w.Source)
+		}
+	}
+}
+
+func TestValidateProject(t *testing.T) {
+	dir := t.TempDir()
+	site := filepath.Join(dir, "site")
+	if err := os.MkdirAll(filepath.Join(site, "content"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := readFile(t, dir, `blog:
+  url: bad-url
+hugo:
+  command: hugo server
+sites:
+  - name: tech
+    hugo:
+      root_dir: `+site+`
+`)
+	path := filepath.Join(dir, "config.yaml")
+	projectFile := filepath.Join(site, ProjectFile)
+	tests := []struct {
+		name    string
+		project string
+		file    string
+		line    int
+	}{
+		{
+			// the site takes the url of the top level in the global config
+			name:    "default_site",
+			project: "default_site: tech\nblog:\n  url: https://tech.example.com\n",
+			file:    path,
+			line:    2,
+		},
+		{
+			name:    "site of the root_dir",
+			project: "blog:\n  name: Tech\n  url: bad-project-url\n",
+			file:    projectFile,
+			line:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(projectFile, []byte(tt.project), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := applyProject(cfg, project{dir: site, configPath: projectFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, err = cfg.WithSite("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := fieldErrors(t, cfg)["blog.url"]
+			if !ok {
+				t.Fatal("no error of blog.url")
+			}
+			if got.File != tt.file || got.Line != tt.line {
+				t.Errorf("error of blog.url is from %s:%d, want %s:%d", got.File, got.Line, tt.file, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package editor
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/babarot/blog/internal/shell"
+)
+
+// Target is the data given to the editor template.
+type Target struct {
+	Path string
+	// Line is 1 when no line is given
+	Line int
+}
+
+// lineTemplates are used for the editors configured without a template
+// so that they still open the file at the line.
+var lineTemplates = map[string]string{
+	"vi":          "+{{.Line}} {{.Path}}",
+	"vim":         "+{{.Line}} {{.Path}}",
+	"nvim":        "+{{.Line}} {{.Path}}",
+	"nano":        "+{{.Line}} {{.Path}}",
+	"micro":       "+{{.Line}} {{.Path}}",
+	"kak":         "+{{.Line}} {{.Path}}",
+	"emacs":       "+{{.Line}} {{.Path}}",
+	"emacsclient": "+{{.Line}} {{.Path}}",
+	"code":        "-g {{.Path}}:{{.Line}}",
+	"codium":      "-g {{.Path}}:{{.Line}}",
+	"cursor":      "-g {{.Path}}:{{.Line}}",
+	"subl":        "{{.Path}}:{{.Line}}",
+	"zed":         "{{.Path}}:{{.Line}}",
+	"hx":          "{{.Path}}:{{.Line}}",
+}
+
+// pathHolder stands for the path while the template is rendered and split
+// into arguments, so that the path is passed as it is whatever it contains.
+const pathHolder = "\x00path\x00"
+
+// Args returns the arguments opening path at line with editor.
+// The editor is either a command such as "vim" or "code --wait", or
+// a template such as "nvim +{{.Line}} {{.Path}}". A line less than 1
+// opens the file as usual.
+func Args(editor, path string, line int) ([]string, error) {
+	text, err := render(editor, line)
+	if err != nil {
+		return nil, err
+	}
+	args, err := shell.Split(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid editor %q: %w", editor, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("invalid editor %q: no command", editor)
+	}
+	for i, arg := range args {
+		args[i] = strings.ReplaceAll(arg, pathHolder, path)
+	}
+	return args, nil
+}
+
+// render returns the command line of editor with pathHolder for the path.
+func render(editor string, line int) (string, error) {
+	editor = strings.TrimSpace(editor)
+	if editor == "" {
+		return "", fmt.Errorf("editor not set")
+	}
+
+	text := editor
+	if !strings.Contains(editor, "{{") {
+		tmpl, ok := lineTemplates[commandName(editor)]
+		if line < 1 || !ok {
+			tmpl = "{{.Path}}"
+		}
+		text = editor + " " + tmpl
+	}
+
+	t, err := template.New("editor").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid editor template %q: %w", editor, err)
+	}
+	var b strings.Builder
+	err = t.Execute(&b, Target{Path: pathHolder, Line: max(line, 1)})
+	if err != nil {
+		return "", fmt.Errorf("invalid editor template %q: %w", editor, err)
+	}
+	return b.String(), nil
+}
+
+// commandName returns the name of the command of editor, skipping the
+// variables set before it such as FOO=1 of "FOO=1 vim".
+func commandName(editor string) string {
+	for _, field := range strings.Fields(editor) {
+		if !assignment.MatchString(field) {
+			return strings.TrimSuffix(filepath.Base(field), ".exe")
+		}
+	}
+	return ""
+}
+
+var assignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// Open returns the command opening path at line with editor in the terminal.
+// The editor using the shell syntax such as $EDITOR is run by the shell
+// with the path quoted, in the quotes written around it if any. The variables in env are added to the environment
+// of the editor.
+func Open(editor string, env map[string]string, path string, line int) (*exec.Cmd, error) {
+	text, err := render(editor, line)
+	if err != nil {
+		return nil, err
+	}
+	var cmd *exec.Cmd
+	if shell.HasSyntax(text) {
+		cmd = shell.CommandLine(shell.Interpolate(text, pathHolder, path))
+	} else {
+		args, err := Args(editor, path, line)
+		if err != nil {
+			return nil, err
+		}
+		cmd = shell.Command(args[0], args[1:]...)
+	}
+	if len(env) > 0 {
+		cmd.Env = shell.Environ(env)
+	}
+	return cmd, nil
+}
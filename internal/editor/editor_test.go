@@ -0,0 +1,119 @@
+package editor
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	const path = "/blog/my post/index.md"
+	tests := []struct {
+		editor string
+		line   int
+		want   []string
+		err    bool
+	}{
+		{editor: "vim", line: 10, want: []string{"vim", "+10", path}},
+		{editor: "vim", line: 0, want: []string{"vim", path}},
+		{editor: "/usr/bin/nvim -p", line: 3, want: []string{"/usr/bin/nvim", "-p", "+3", path}},
+		{editor: "code --wait", line: 3, want: []string{"code", "--wait", "-g", path + ":3"}},
+		{editor: "ed", line: 3, want: []string{"ed", path}},
+		{editor: "nvim +{{.Line}} {{.Path}}", line: 0, want: []string{"nvim", "+1", path}},
+		{editor: `subl "{{.Path}}:{{.Line}}"`, line: 7, want: []string{"subl", path + ":7"}},
+		{editor: "", err: true},
+		{editor: "vim {{.Nope}}", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			got, err := Args(tt.editor, path, tt.line)
+			if (err != nil) != tt.err {
+				t.Fatalf("Args() error = %v, want error %v", err, tt.err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		editor string
+		want   string
+	}{
+		{editor: "vim", want: "vim"},
+		{editor: "/usr/local/bin/nvim -p", want: "nvim"},
+		{editor: "FOO=1 vim", want: "vim"},
+		{editor: "FOO=1 BAR=2 code --wait", want: "code"},
+		{editor: "vim --cmd=set", want: "vim"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			if got := commandName(tt.editor); got != tt.want {
+				t.Errorf("commandName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the editor is run by cmd on Windows")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not found")
+	}
+	const path = `/blog/it's a "$HOME" post/index.md`
+	tests := []struct {
+		name   string
+		editor string
+		line   int
+		want   string
+	}{
+		{
+			name:   "quotes around the path",
+			editor: `printf '%s|' {{.Path}} '{{.Path}}' "{{.Path}}" "at {{.Path}}:{{.Line}}"; true`,
+			line:   4,
+			want:   path + "|" + path + "|" + path + "|at " + path + ":4|",
+		},
+		{
+			name:   "variable set before the editor",
+			editor: `FOO=1 printf '%s|'`,
+			want:   path + "|",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := Open(tt.editor, nil, path, tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			cmd.Stdin, cmd.Stdout = nil, &out
+			if err := cmd.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the editor is run by cmd on Windows")
+	}
+	// the variable makes it run by the shell, keeping the line
+	cmd, err := Open("FOO=1 vim", nil, "/blog/post.md", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := cmd.Args[len(cmd.Args)-1]; !strings.Contains(line, "vim +12 /blog/post.md") {
+		t.Errorf("command line = %q, want vim +12 /blog/post.md", line)
+	}
+}
@@ -13,6 +13,8 @@ const (
 var (
 	BLOG_LOG_PATH    string
 	BLOG_CONFIG_PATH string
+	// BLOG_STATE_DIR holds the files shared by the running blog processes
+	BLOG_STATE_DIR string
 )
 
 func init() {
@@ -30,6 +32,8 @@ func init() {
 			configDir = filepath.Join(homeDir, defaultXDGConfigDirname)
 		}
 		BLOG_CONFIG_PATH = filepath.Join(configDir, "blog", "config.yaml")
+	} else {
+		BLOG_CONFIG_PATH = e
 	}
 
 	if e := os.Getenv("BLOG_LOG_PATH"); e == "" {
@@ -42,5 +46,14 @@ func init() {
 			dataDir = filepath.Join(homeDir, defaultXDGDataDirname)
 		}
 		BLOG_LOG_PATH = filepath.Join(dataDir, "blog", "debug.log")
+	} else {
+		BLOG_LOG_PATH = e
+	}
+
+	// the state is kept in the data directory next to the log
+	if e := os.Getenv("BLOG_STATE_DIR"); e == "" {
+		BLOG_STATE_DIR = filepath.Dir(BLOG_LOG_PATH)
+	} else {
+		BLOG_STATE_DIR = e
 	}
 }
@@ -0,0 +1,90 @@
+package hugo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Level int
+
+const (
+	Info Level = iota
+	Warn
+	Error
+)
+
+func (l Level) String() string {
+	switch l {
+	case Warn:
+		return "warn"
+	case Error:
+		return "error"
+	}
+	return "info"
+}
+
+// Event is a line of the hugo output with the location it refers to.
+type Event struct {
+	Level   Level
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Text    string
+	// Failed tells the line reports the build failed, which not every
+	// error does, e.g. the deprecations
+	Failed bool
+}
+
+var (
+	// e.g. "ERROR 2024/01/02 15:04:05 ", "WARN  ", "Error: "
+	levelPattern = regexp.MustCompile(`^(ERROR|WARN|Error:)\s+(?:\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}\s+)?`)
+	// e.g. "/site/content/post/foo/index.md:12:5": (hugo quotes the paths)
+	quotedLocationPattern = regexp.MustCompile(`"([^"]+?):(\d+)(?::(\d+))?":\s*`)
+	// e.g. /site/layouts/single.html:4:
+	locationPattern = regexp.MustCompile(`([^\s"]+?):(\d+)(?::(\d+))?:\s*`)
+	// the ERROR lines of the failed builds, e.g. "Rebuild failed: ..."
+	failurePattern = regexp.MustCompile(`^(?:Rebuild failed|error building site)\b`)
+)
+
+// ParseEvent parses a line of the hugo output. The first location with
+// a line number is taken since hugo wraps the error of the content with
+// the one of the template rendering it.
+func ParseEvent(line string) Event {
+	ev := Event{Level: Info, Message: strings.TrimSpace(line), Text: line}
+	m := levelPattern.FindStringSubmatch(line)
+	if m == nil {
+		return ev
+	}
+	rest := line[len(m[0]):]
+	switch m[1] {
+	case "WARN":
+		ev.Level = Warn
+	case "ERROR":
+		ev.Level = Error
+		ev.Failed = failurePattern.MatchString(rest)
+	default:
+		// hugo exits with "Error:"
+		ev.Level = Error
+		ev.Failed = true
+	}
+	ev.Message = strings.TrimSpace(rest)
+
+	loc := quotedLocationPattern.FindStringSubmatchIndex(rest)
+	if loc == nil {
+		loc = locationPattern.FindStringSubmatchIndex(rest)
+	}
+	if loc == nil {
+		return ev
+	}
+	ev.File = rest[loc[2]:loc[3]]
+	ev.Line, _ = strconv.Atoi(rest[loc[4]:loc[5]])
+	if loc[6] >= 0 {
+		ev.Column, _ = strconv.Atoi(rest[loc[6]:loc[7]])
+	}
+	if msg := strings.TrimSpace(rest[loc[1]:]); msg != "" {
+		ev.Message = msg
+	}
+	return ev
+}
@@ -0,0 +1,147 @@
+package hugo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want Event
+	}{
+		{
+			name: "rebuild failed",
+			line: `ERROR Rebuild failed: process: readAndProcessContent: "/site/content/post/2024/hello/index.md:3:1": failed to unmarshal YAML: yaml: line 3: could not find expected ':'`,
+			want: Event{
+				Level:   Error,
+				File:    "/site/content/post/2024/hello/index.md",
+				Line:    3,
+				Column:  1,
+				Message: "failed to unmarshal YAML: yaml: line 3: could not find expected ':'",
+				Failed:  true,
+			},
+		},
+		{
+			name: "rebuild failed with the time",
+			line: `ERROR 2023/05/10 10:00:00 Rebuild failed: "/site/content/post/hello.md:5:1": unrecognized character in shortcode action`,
+			want: Event{
+				Level:   Error,
+				File:    "/site/content/post/hello.md",
+				Line:    5,
+				Column:  1,
+				Message: "unrecognized character in shortcode action",
+				Failed:  true,
+			},
+		},
+		{
+			name: "build failed",
+			line: `Error: error building site: render: failed to render pages: render of "page" failed: "/site/layouts/_default/single.html:12:7": execute of template failed: template: _default/single.html:12:7: executing "main" at <.Foo>: can't evaluate field Foo in type page.Page`,
+			want: Event{
+				Level:   Error,
+				File:    "/site/layouts/_default/single.html",
+				Line:    12,
+				Column:  7,
+				Message: `execute of template failed: template: _default/single.html:12:7: executing "main" at <.Foo>: can't evaluate field Foo in type page.Page`,
+				Failed:  true,
+			},
+		},
+		{
+			name: "config failed",
+			line: `Error: command error: failed to load config: "/site/hugo.toml:3:1": unmarshal failed: toml: expected character =`,
+			want: Event{
+				Level:   Error,
+				File:    "/site/hugo.toml",
+				Line:    3,
+				Column:  1,
+				Message: "unmarshal failed: toml: expected character =",
+				Failed:  true,
+			},
+		},
+		{
+			name: "logged error",
+			line: `ERROR render of "page" failed: "/site/layouts/_default/single.html:12:7": execute of template failed`,
+			want: Event{
+				Level:   Error,
+				File:    "/site/layouts/_default/single.html",
+				Line:    12,
+				Column:  7,
+				Message: "execute of template failed",
+			},
+		},
+		{
+			name: "deprecated error",
+			line: "ERROR deprecated: site config key paginate was deprecated in Hugo v0.128.0 and will be removed in Hugo 0.138.0. Use pagination.pagerSize instead.",
+			want: Event{
+				Level:   Error,
+				Message: "deprecated: site config key paginate was deprecated in Hugo v0.128.0 and will be removed in Hugo 0.138.0. Use pagination.pagerSize instead.",
+			},
+		},
+		{
+			name: "warning",
+			line: `WARN  found no layout file for "html" for kind "page": You should create a template file which matches Hugo Layouts Lookup Rules for this combination.`,
+			want: Event{
+				Level:   Warn,
+				Message: `found no layout file for "html" for kind "page": You should create a template file which matches Hugo Layouts Lookup Rules for this combination.`,
+			},
+		},
+		{
+			name: "info",
+			line: "Web Server is available at http://localhost:1313/ (bind address 127.0.0.1) ",
+			want: Event{
+				Level:   Info,
+				Message: "Web Server is available at http://localhost:1313/ (bind address 127.0.0.1)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			want.Text = tt.line
+			if got := ParseEvent(tt.line); got != want {
+				t.Errorf("ParseEvent() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestHandleLine(t *testing.T) {
+	tests := []struct {
+		fixture string
+		state   State
+		message string
+		events  int
+	}{
+		{fixture: "deprecated.txt", state: Ready, events: 2},
+		{fixture: "rebuild-failed.txt", state: Failed, message: "failed to unmarshal YAML: yaml: line 3: could not find expected ':'", events: 1},
+		{fixture: "recovered.txt", state: Ready, events: 1},
+		{fixture: "logged-errors.txt", state: Failed, message: "execute of template failed: template: _default/single.html:12:7: executing \"main\" at <.Foo>: can't evaluate field Foo in type page.Page", events: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var events int
+			s := &Server{OnEvent: func(Event) { events++ }}
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				s.handleLine(scanner.Text())
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Status(); got.State != tt.state || got.Message != tt.message {
+				t.Errorf("Status() = %v %q, want %v %q", got.State, got.Message, tt.state, tt.message)
+			}
+			if events != tt.events {
+				t.Errorf("%d events, want %d", events, tt.events)
+			}
+		})
+	}
+}
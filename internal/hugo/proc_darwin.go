@@ -0,0 +1,20 @@
+//go:build darwin
+
+package hugo
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// processStart returns the start time of the process, or "" if it is
+// unknown.
+func processStart(pid int) string {
+	info, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil || info.Proc.P_pid != int32(pid) {
+		return ""
+	}
+	t := info.Proc.P_starttime
+	return fmt.Sprintf("%d.%06d", t.Sec, t.Usec)
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package hugo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// processStart returns the start time of the process in clock ticks since
+// boot, or "" if it is unknown.
+func processStart(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return ""
+	}
+	// the fields follow the command name in parentheses, which may have
+	// spaces, and the start time is the 22nd of the whole
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return ""
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 20 {
+		return ""
+	}
+	return fields[19]
+}
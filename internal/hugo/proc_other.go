@@ -0,0 +1,9 @@
+//go:build !windows && !linux && !darwin
+
+package hugo
+
+// processStart returns "" as the start time of the process is unknown,
+// so the process is identified by its PID alone.
+func processStart(pid int) string {
+	return ""
+}
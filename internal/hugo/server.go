@@ -0,0 +1,489 @@
+package hugo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/babarot/blog/internal/shell"
+	"github.com/nxadm/tail"
+)
+
+type State int
+
+const (
+	Starting State = iota
+	Ready
+	Building
+	Failed
+	Stopped
+)
+
+func (s State) String() string {
+	switch s {
+	case Starting:
+		return "starting"
+	case Ready:
+		return "ready"
+	case Building:
+		return "building"
+	case Failed:
+		return "failed"
+	case Stopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// Status is reported to OnStatus every time the server changes its state.
+type Status struct {
+	State   State
+	Message string
+	// Port is the port the server actually listens on.
+	Port int
+}
+
+const (
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+	// a run longer than this is not regarded as a crash loop
+	stableRun = time.Minute
+	// give up after crashing this many times in a row
+	maxRestarts = 5
+)
+
+// errStopped is returned when the server was stopped by "blog server stop".
+var errStopped = errors.New("hugo server was stopped")
+
+// Server supervises a "hugo server" process. The process is shared by the
+// blog processes working on the same site through the files in StateDir:
+// the first one starts it, the others attach to it, and the last one to
+// exit stops it. Any of them restarts the process when it crashes.
+type Server struct {
+	Command string
+	Dir     string
+	// Port is the preferred port of the server. When it is taken, the next
+	// free one is used instead and passed to Command (see command).
+	// Zero leaves the port to Command.
+	Port     int
+	Env      map[string]string
+	StateDir string
+	Log      io.Writer
+
+	OnStatus func(Status)
+	// OnEvent is called with the warnings and errors in the output.
+	OnEvent func(Event)
+
+	mu     sync.Mutex
+	status Status
+	port   int
+	// lastError is the last error not failing the build by itself
+	lastError string
+}
+
+func (s *Server) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *Server) setStatus(state State, message string) {
+	s.mu.Lock()
+	status := Status{State: state, Message: message, Port: s.port}
+	if s.status == status {
+		s.mu.Unlock()
+		return
+	}
+	s.status = status
+	s.mu.Unlock()
+
+	slog.Debug("hugo server", "state", state, "message", message, "port", status.Port)
+	if s.OnStatus != nil {
+		s.OnStatus(status)
+	}
+}
+
+func (s *Server) setPort(port int) {
+	s.mu.Lock()
+	s.port = port
+	s.mu.Unlock()
+}
+
+// Run starts or attaches to the server and restarts it when it crashes.
+// It blocks until ctx is canceled or the server keeps crashing.
+func (s *Server) Run(ctx context.Context) error {
+	store := newStore(s.StateDir, s.Dir)
+	defer func() {
+		if err := s.release(store); err != nil {
+			slog.Error("failed to release hugo server", "error", err)
+		}
+	}()
+
+	backoff := initialBackoff
+	restarts := 0
+	for {
+		started := time.Now()
+		err := s.runOnce(ctx, store)
+		if ctx.Err() != nil {
+			s.setStatus(Stopped, "")
+			return nil
+		}
+		if errors.Is(err, errStopped) {
+			s.setStatus(Stopped, err.Error())
+			return nil
+		}
+		if err == nil {
+			err = errors.New("exited unexpectedly")
+		}
+		if time.Since(started) > stableRun {
+			backoff = initialBackoff
+			restarts = 0
+		}
+		restarts++
+		if restarts > maxRestarts {
+			s.setStatus(Failed, fmt.Sprintf("hugo server gave up: %v", err))
+			return fmt.Errorf("hugo server failed %d times: %w", maxRestarts, err)
+		}
+		s.setStatus(Failed, fmt.Sprintf("hugo server crashed (%v), restarting in %s", err, backoff))
+
+		select {
+		case <-ctx.Done():
+			s.setStatus(Stopped, "")
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (s *Server) runOnce(ctx context.Context, store store) error {
+	s.setStatus(Starting, "")
+
+	inst, cmd, err := s.acquire(store)
+	if err != nil {
+		return err
+	}
+	if inst.Port != s.Status().Port {
+		s.setPort(inst.Port)
+		s.setStatus(Starting, "")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go s.follow(ctx, inst.LogPath, cmd != nil)
+	go s.waitReady(ctx, inst.Port)
+
+	exited := make(chan error, 1)
+	if cmd != nil {
+		// started by this process
+		go func() { exited <- cmd.Wait() }()
+	} else {
+		// attached to the server started by another process
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+				if !inst.Alive() {
+					exited <- errors.New("shared server exited")
+					return
+				}
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-exited:
+		return err
+	}
+}
+
+// acquire attaches to the running server or starts a new one.
+// The command is returned only when it has been started by this process.
+func (s *Server) acquire(store store) (*Instance, *exec.Cmd, error) {
+	var inst *Instance
+	var cmd *exec.Cmd
+	err := store.locked(func() error {
+		var err error
+		inst, err = store.read()
+		if err != nil {
+			return err
+		}
+		self := os.Getpid()
+		if inst != nil {
+			inst.pruneClients()
+			if inst.Stopped {
+				if slices.Contains(inst.Clients, self) {
+					inst.Clients = slices.DeleteFunc(inst.Clients, func(pid int) bool { return pid == self })
+					return errors.Join(errStopped, store.write(inst))
+				}
+				// a new client starts the server again
+				inst.Stopped = false
+			}
+			if inst.Alive() {
+				slog.Debug("attach to hugo server", "pid", inst.PID, "port", inst.Port)
+				if !slices.Contains(inst.Clients, self) {
+					inst.Clients = append(inst.Clients, self)
+				}
+				return store.write(inst)
+			}
+		}
+
+		command, port := s.Command, s.Port
+		if port > 0 {
+			port, err = freePort(port, store.usedPorts())
+			if err != nil {
+				return err
+			}
+			if port != s.Port {
+				slog.Warn("dev port is in use, use another one", "dev_port", s.Port, "port", port)
+			}
+		}
+		command, err = s.command(port)
+		if err != nil {
+			return err
+		}
+
+		log, err := os.Create(store.logPath())
+		if err != nil {
+			return err
+		}
+		defer log.Close()
+		cmd, err = shell.Shell{
+			Command: command,
+			Env:     s.Env,
+			Dir:     s.Dir,
+			Stdout:  log,
+			Stderr:  log,
+			Detach:  true,
+		}.Start(context.Background())
+		if err != nil {
+			return err
+		}
+		clients := []int{self}
+		if inst != nil {
+			clients = append(inst.Clients, clients...)
+			clients = slices.Compact(slices.Sorted(slices.Values(clients)))
+		}
+		inst = &Instance{
+			PID:          cmd.Process.Pid,
+			ProcessStart: processStart(cmd.Process.Pid),
+			Port:         port,
+			Dir:          s.Dir,
+			Command:      command,
+			LogPath:      store.logPath(),
+			Clients:      clients,
+			StartedAt:    time.Now(),
+		}
+		slog.Debug("start hugo server", "pid", inst.PID, "port", inst.Port)
+		return store.write(inst)
+	})
+	return inst, cmd, err
+}
+
+// command returns Command listening on the port. A template is given the
+// port as {{.Port}}, and a plain hugo command gets --port and --baseURL
+// unless it has them. Any other command, e.g. "make serve", is run as it
+// is, so it fails when the port is not the preferred one.
+func (s *Server) command(port int) (string, error) {
+	if strings.Contains(s.Command, "{{") {
+		tmpl, err := template.New("command").Option("missingkey=error").Parse(s.Command)
+		if err != nil {
+			return "", fmt.Errorf("invalid hugo.command: %w", err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, struct{ Port int }{port}); err != nil {
+			return "", fmt.Errorf("invalid hugo.command: %w", err)
+		}
+		return b.String(), nil
+	}
+	if port == 0 {
+		return s.Command, nil
+	}
+	args, err := shell.Split(s.Command)
+	if err == nil && len(args) > 0 && !shell.HasSyntax(s.Command) &&
+		strings.TrimSuffix(filepath.Base(args[0]), ".exe") == "hugo" && !hasFlag(args, "--port", "-p") {
+		command := fmt.Sprintf("%s --port %d", s.Command, port)
+		if !hasFlag(args, "--baseURL", "-b") {
+			command += fmt.Sprintf(" --baseURL http://localhost:%d/", port)
+		}
+		return command, nil
+	}
+	if port != s.Port {
+		return "", fmt.Errorf("dev port %d is in use and hugo.command cannot be given another one: use {{.Port}} in it", s.Port)
+	}
+	return s.Command, nil
+}
+
+// hasFlag reports whether the arguments have any of the flags.
+func hasFlag(args []string, flags ...string) bool {
+	return slices.ContainsFunc(args, func(arg string) bool {
+		name, _, _ := strings.Cut(arg, "=")
+		return slices.Contains(flags, name)
+	})
+}
+
+// release detaches from the server and stops it if no one else uses it.
+func (s *Server) release(store store) error {
+	return store.locked(func() error {
+		inst, err := store.read()
+		if err != nil || inst == nil {
+			return err
+		}
+		self := os.Getpid()
+		inst.Clients = slices.DeleteFunc(inst.Clients, func(pid int) bool { return pid == self })
+		inst.pruneClients()
+		if len(inst.Clients) > 0 {
+			slog.Debug("detach from hugo server", "pid", inst.PID, "clients", inst.Clients)
+			return store.write(inst)
+		}
+		if inst.Alive() {
+			slog.Debug("stop hugo server", "pid", inst.PID)
+			if err := terminate(inst.PID); err != nil {
+				return err
+			}
+		}
+		return store.remove()
+	})
+}
+
+// follow reads the output of the server written to the log file.
+func (s *Server) follow(ctx context.Context, path string, fromStart bool) {
+	config := tail.Config{
+		ReOpen: true,
+		Poll:   true,
+		Follow: true,
+		Logger: tail.DiscardingLogger,
+	}
+	if !fromStart {
+		config.Location = &tail.SeekInfo{Offset: 0, Whence: io.SeekEnd}
+	}
+	t, err := tail.TailFile(path, config)
+	if err != nil {
+		slog.Error("failed to follow hugo output", "error", err)
+		return
+	}
+	defer t.Cleanup()
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line, ok := <-t.Lines:
+			if !ok {
+				return
+			}
+			if s.Log != nil {
+				fmt.Fprintln(s.Log, line.Text)
+			}
+			s.handleLine(line.Text)
+		}
+	}
+}
+
+// freePort returns the first port from the given one that is neither
+// used by another site nor bound by any other process.
+func freePort(port int, used []int) (int, error) {
+	const tries = 100
+	for p := port; p < port+tries && p <= 65535; p++ {
+		if slices.Contains(used, p) {
+			continue
+		}
+		l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(p)))
+		if err != nil {
+			continue
+		}
+		l.Close()
+		return p, nil
+	}
+	return 0, fmt.Errorf("no free port found in %d-%d", port, port+tries-1)
+}
+
+// waitReady polls the HTTP port until the server answers a request.
+func (s *Server) waitReady(ctx context.Context, port int) {
+	if port == 0 {
+		return
+	}
+	url := "http://" + net.JoinHostPort("localhost", strconv.Itoa(port)) + "/"
+	client := &http.Client{Timeout: time.Second}
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			continue
+		}
+		// any status will do as it is hugo that answers
+		resp.Body.Close()
+		if s.Status().State == Starting {
+			s.setStatus(Ready, "")
+		}
+		return
+	}
+}
+
+// loggedPattern is the message of the build failed by the errors logged
+// before, e.g. "error building site: logged 1 error(s)".
+var loggedPattern = regexp.MustCompile(`logged \d+ error\(s\)$`)
+
+// handleLine updates the state from the output of hugo.
+func (s *Server) handleLine(line string) {
+	ev := ParseEvent(line)
+	if ev.Level > Info && s.OnEvent != nil {
+		s.OnEvent(ev)
+	}
+	switch {
+	case ev.Failed:
+		msg := ev.Message
+		s.mu.Lock()
+		if loggedPattern.MatchString(msg) && s.lastError != "" {
+			// the summary of the errors logged before
+			msg = s.lastError
+		}
+		s.mu.Unlock()
+		s.setStatus(Failed, msg)
+	case ev.Level == Error:
+		s.mu.Lock()
+		s.lastError = ev.Message
+		s.mu.Unlock()
+	case strings.Contains(line, "Change detected, rebuilding site"),
+		strings.Contains(line, "Change of config file detected"):
+		s.setStatus(Building, "")
+	case strings.HasPrefix(line, "Web Server is available at"),
+		strings.HasPrefix(line, "Total in"),
+		strings.HasPrefix(line, "Rebuilt in"):
+		if s.Status().State != Failed || !strings.HasPrefix(line, "Total in") {
+			s.setStatus(Ready, "")
+		}
+	}
+}
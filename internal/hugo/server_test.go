@@ -0,0 +1,39 @@
+package hugo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWaitReady(t *testing.T) {
+	t.Run("http", func(t *testing.T) {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		defer ts.Close()
+		s := &Server{}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.waitReady(ctx, ts.Listener.Addr().(*net.TCPAddr).Port)
+		if got := s.Status().State; got != Ready {
+			t.Errorf("state = %v, want %v", got, Ready)
+		}
+	})
+	t.Run("no answer", func(t *testing.T) {
+		// the port is open but nothing answers on it
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		s := &Server{}
+		ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+		defer cancel()
+		s.waitReady(ctx, l.Addr().(*net.TCPAddr).Port)
+		if got := s.Status().State; got != Starting {
+			t.Errorf("state = %v, want %v", got, Starting)
+		}
+	})
+}
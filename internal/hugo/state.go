@@ -0,0 +1,207 @@
+package hugo
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Instance is a hugo server shared by the blog processes working on
+// the same site. It is saved as a JSON file under the state directory.
+type Instance struct {
+	PID       int       `json:"pid"`
+	Port      int       `json:"port"`
+	Dir       string    `json:"dir"`
+	Command   string    `json:"command"`
+	LogPath   string    `json:"log_path"`
+	Clients   []int     `json:"clients"`
+	StartedAt time.Time `json:"started_at"`
+	// ProcessStart is the start time of the process reported by the OS,
+	// which tells the server from another process reusing its PID.
+	ProcessStart string `json:"process_start,omitempty"`
+	// Stopped tells the clients that the server was stopped on purpose
+	// and should not be restarted.
+	Stopped bool `json:"stopped"`
+}
+
+func (i Instance) Alive() bool {
+	if !processAlive(i.PID) {
+		return false
+	}
+	if i.ProcessStart == "" {
+		return true
+	}
+	start := processStart(i.PID)
+	return start == "" || start == i.ProcessStart
+}
+
+// store is the set of files for the server of one site.
+type store struct {
+	dir string
+	key string
+}
+
+func newStore(stateDir, siteDir string) store {
+	sum := sha1.Sum([]byte(siteDir))
+	return store{dir: stateDir, key: hex.EncodeToString(sum[:])[:12]}
+}
+
+func (s store) statePath() string { return filepath.Join(s.dir, "hugo-"+s.key+".json") }
+func (s store) lockPath() string  { return filepath.Join(s.dir, "hugo-"+s.key+".lock") }
+func (s store) logPath() string   { return filepath.Join(s.dir, "hugo-"+s.key+".log") }
+
+// locked runs fn while holding the lock shared by the blog processes.
+func (s store) locked(fn func() error) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.lockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", s.lockPath(), err)
+	}
+	defer unlockFile(f)
+	return fn()
+}
+
+// read returns the saved instance, or nil if there is none.
+func (s store) read() (*Instance, error) {
+	data, err := os.ReadFile(s.statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var inst Instance
+	if err := json.Unmarshal(data, &inst); err != nil {
+		return nil, fmt.Errorf("broken state file %s: %w", s.statePath(), err)
+	}
+	return &inst, nil
+}
+
+func (s store) write(inst *Instance) error {
+	data, err := json.MarshalIndent(inst, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.statePath(), data, 0644)
+}
+
+func (s store) remove() error {
+	err := os.Remove(s.statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// usedPorts returns the ports of the live servers of the other sites.
+// The state files are read without the lock since the lock of this store
+// is held by the caller and a stale port only makes us skip it.
+func (s store) usedPorts() []int {
+	files, _ := filepath.Glob(filepath.Join(s.dir, "hugo-*.json"))
+	var ports []int
+	for _, file := range files {
+		if file == s.statePath() {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var inst Instance
+		if json.Unmarshal(data, &inst) != nil || !inst.Alive() {
+			continue
+		}
+		ports = append(ports, inst.Port)
+	}
+	return ports
+}
+
+// pruneClients drops the clients that have exited without releasing.
+func (i *Instance) pruneClients() {
+	i.Clients = slices.DeleteFunc(i.Clients, func(pid int) bool {
+		return !processAlive(pid)
+	})
+}
+
+// Instances returns the servers recorded under the state directory.
+func Instances(stateDir string) ([]Instance, error) {
+	files, err := filepath.Glob(filepath.Join(stateDir, "hugo-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var instances []Instance
+	for _, file := range files {
+		key := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "hugo-"), ".json")
+		s := store{dir: stateDir, key: key}
+		err := s.locked(func() error {
+			inst, err := s.read()
+			if err != nil || inst == nil {
+				return err
+			}
+			inst.pruneClients()
+			instances = append(instances, *inst)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return instances, nil
+}
+
+// Lookup returns the live server of the site, or nil if there is none.
+func Lookup(stateDir, siteDir string) (*Instance, error) {
+	s := newStore(stateDir, siteDir)
+	var inst *Instance
+	err := s.locked(func() error {
+		var err error
+		inst, err = s.read()
+		if err != nil || inst == nil {
+			return err
+		}
+		if !inst.Alive() || inst.Stopped {
+			inst = nil
+		}
+		return nil
+	})
+	return inst, err
+}
+
+// Stop stops the shared server of the site regardless of its clients.
+// The clients see the server gone and stop following it.
+func Stop(stateDir, siteDir string) error {
+	s := newStore(stateDir, siteDir)
+	return s.locked(func() error {
+		inst, err := s.read()
+		if err != nil {
+			return err
+		}
+		if inst == nil {
+			return fmt.Errorf("no hugo server is running for %s", siteDir)
+		}
+		if inst.Alive() {
+			if err := terminate(inst.PID); err != nil {
+				return fmt.Errorf("failed to stop hugo server (pid %d): %w", inst.PID, err)
+			}
+		}
+		inst.pruneClients()
+		if len(inst.Clients) == 0 {
+			return s.remove()
+		}
+		inst.Stopped = true
+		return s.write(inst)
+	})
+}
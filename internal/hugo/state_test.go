@@ -0,0 +1,136 @@
+package hugo
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// exitedPID returns the PID of a process which has exited.
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	// the test binary running no tests
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestAlive(t *testing.T) {
+	pid := os.Getpid()
+	start := processStart(pid)
+	tests := []struct {
+		name string
+		inst Instance
+		want bool
+	}{
+		{name: "running", inst: Instance{PID: pid, ProcessStart: start}, want: true},
+		{name: "without the start time", inst: Instance{PID: pid}, want: true},
+		{name: "stale PID", inst: Instance{PID: exitedPID(t)}, want: false},
+		{name: "no PID", inst: Instance{}, want: false},
+		// the start time is not known on every OS
+		{name: "PID reused by another process", inst: Instance{PID: pid, ProcessStart: start + "0"}, want: start == ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.inst.Alive(); got != tt.want {
+				t.Errorf("Alive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	pid := os.Getpid()
+	stale := exitedPID(t)
+	tests := []struct {
+		name string
+		inst Instance
+		want bool
+	}{
+		{name: "running", inst: Instance{PID: pid, Port: 1313, ProcessStart: processStart(pid)}, want: true},
+		{name: "stale PID", inst: Instance{PID: stale, Port: 1313}, want: false},
+		{name: "stopped", inst: Instance{PID: pid, Port: 1313, Stopped: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := newStore(dir, "/srv/blog")
+			if err := s.write(&tt.inst); err != nil {
+				t.Fatal(err)
+			}
+			inst, err := Lookup(dir, "/srv/blog")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := inst != nil; got != tt.want {
+				t.Errorf("Lookup() found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopStale(t *testing.T) {
+	dir := t.TempDir()
+	s := newStore(dir, "/srv/blog")
+	inst := &Instance{PID: exitedPID(t), Port: 1313, Clients: []int{exitedPID(t)}}
+	if err := s.write(inst); err != nil {
+		t.Fatal(err)
+	}
+	if err := Stop(dir, "/srv/blog"); err != nil {
+		t.Fatal(err)
+	}
+	// no client is left to tell the server was stopped
+	if _, err := os.Stat(s.statePath()); !os.IsNotExist(err) {
+		t.Errorf("state file is left: %v", err)
+	}
+}
+
+func TestUsedPorts(t *testing.T) {
+	dir := t.TempDir()
+	pid := os.Getpid()
+	for site, inst := range map[string]Instance{
+		"/srv/a": {PID: pid, Port: 1313, ProcessStart: processStart(pid)},
+		"/srv/b": {PID: exitedPID(t), Port: 1314},
+		"/srv/c": {PID: pid, Port: 1315, ProcessStart: processStart(pid)},
+	} {
+		if err := newStore(dir, site).write(&inst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ports := newStore(dir, "/srv/c").usedPorts()
+	if len(ports) != 1 || ports[0] != 1313 {
+		t.Errorf("usedPorts() = %v, want [1313]", ports)
+	}
+}
+
+func TestLocked(t *testing.T) {
+	s := newStore(t.TempDir(), "/srv/blog")
+	held := make(chan struct{})
+	release := make(chan struct{})
+	go s.locked(func() error {
+		close(held)
+		<-release
+		return nil
+	})
+	<-held
+
+	done := make(chan struct{})
+	go func() {
+		s.locked(func() error { return nil })
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("the lock is taken while it is held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the lock is not taken after it is released")
+	}
+}
@@ -0,0 +1,222 @@
+package shell
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Quote quotes s so that the shell running Shell.Command
+// passes it to the command as a single argument.
+func Quote(s string) string {
+	if runtime.GOOS == "windows" {
+		return quoteWindows(s)
+	}
+	return quotePOSIX(s)
+}
+
+// Interpolate replaces placeholder in the command line with s quoted for
+// where it is: as an argument of its own, or in single or double quotes
+// written around it, so that the quotes of a template such as
+// $VISUAL "{{.Path}}" are not passed to the command.
+func Interpolate(line, placeholder, s string) string {
+	if placeholder == "" {
+		return line
+	}
+	windows := runtime.GOOS == "windows"
+	var (
+		b     strings.Builder
+		quote byte
+	)
+	for i := 0; i < len(line); i++ {
+		if strings.HasPrefix(line[i:], placeholder) {
+			b.WriteString(quoteIn(quote, s, windows))
+			i += len(placeholder) - 1
+			continue
+		}
+		c := line[i]
+		b.WriteByte(c)
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case quote == '"':
+			switch {
+			case c == '"':
+				quote = 0
+			case !windows && c == '\\' && i+1 < len(line):
+				i++
+				b.WriteByte(line[i])
+			}
+		case c == '"', c == '\'' && !windows:
+			quote = c
+		case !windows && c == '\\' && i+1 < len(line):
+			i++
+			b.WriteByte(line[i])
+		}
+	}
+	return b.String()
+}
+
+// quoteIn quotes s to be put in the quotes of the command line.
+func quoteIn(quote byte, s string, windows bool) string {
+	switch {
+	case windows && quote == '"':
+		return strings.ReplaceAll(strings.ReplaceAll(s, `"`, `""`), "%", `"^%"`)
+	case windows:
+		return quoteWindows(s)
+	case quote == '\'':
+		return strings.ReplaceAll(s, "'", `'\''`)
+	case quote == '"':
+		var b strings.Builder
+		for _, r := range s {
+			if strings.ContainsRune("\"\\$`", r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+	return quotePOSIX(s)
+}
+
+// Split splits a command line written in the config into arguments in
+// the way the shell does, except that variables and globs are kept as
+// they are. Backslashes are literal on Windows since they are the path
+// separator there.
+func Split(s string) ([]string, error) {
+	escape := runtime.GOOS != "windows"
+	var (
+		args   []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+	)
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case escape && r == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]):
+				i++
+				word.WriteRune(runes[i])
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case escape && r == '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// cmdBuiltins are the commands of cmd.exe which are not executables.
+var cmdBuiltins = []string{"start", "call", "echo", "set", "cd", "type", "dir", "del", "copy", "move"}
+
+// HasSyntax reports whether the command line needs the shell to run,
+// e.g. for pipes, redirects, variables, globs, command lists, a leading
+// variable assignment or a builtin of cmd.exe, which Split does not
+// interpret.
+func HasSyntax(s string) bool {
+	escape := runtime.GOOS != "windows"
+	special := "|&;<>()$`*?[]{}~#!\n"
+	if !escape {
+		special = "|&<>()%^!\n"
+	}
+	var quote rune
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case escape && r == '\\':
+				i++
+			case strings.ContainsRune(special, r) && strings.ContainsRune("$`%!", r):
+				return true
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case escape && r == '\\':
+			i++
+		case strings.ContainsRune(special, r):
+			return true
+		}
+	}
+	args, err := Split(s)
+	if err != nil || len(args) == 0 {
+		return err != nil
+	}
+	if !escape && slices.Contains(cmdBuiltins, strings.ToLower(args[0])) {
+		return true
+	}
+	name, _, ok := strings.Cut(args[0], "=")
+	return ok && name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) < 0
+}
+
+func quotePOSIX(s string) string {
+	if s != "" && strings.IndexFunc(s, isUnsafe) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteWindows(s string) string {
+	if s != "" && strings.IndexFunc(s, isUnsafe) < 0 && !strings.Contains(s, "%") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `"`, `""`)
+	// cmd expands %VAR% even in quotes, so % is escaped out of them
+	s = strings.ReplaceAll(s, "%", `"^%"`)
+	return `"` + s + `"`
+}
+
+func isUnsafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./:=+,@%", r) && r < 0x80
+}
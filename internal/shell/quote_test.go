@@ -0,0 +1,188 @@
+package shell
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("backslashes are literal on Windows")
+	}
+	tests := []struct {
+		in   string
+		want []string
+		err  bool
+	}{
+		{in: "vim -f", want: []string{"vim", "-f"}},
+		{in: "  code   --wait  ", want: []string{"code", "--wait"}},
+		{in: `code --wait "my file.md"`, want: []string{"code", "--wait", "my file.md"}},
+		{in: `'a b' c`, want: []string{"a b", "c"}},
+		{in: `'it'\''s'`, want: []string{"it's"}},
+		{in: `a\ b`, want: []string{"a b"}},
+		{in: `"a\"b"`, want: []string{`a"b`}},
+		{in: `"a\nb"`, want: []string{`a\nb`}},
+		{in: `'a\"b'`, want: []string{`a\"b`}},
+		{in: `a"b c"d`, want: []string{"ab cd"}},
+		{in: `a "" b`, want: []string{"a", "", "b"}},
+		{in: `''`, want: []string{""}},
+		{in: "$EDITOR -f", want: []string{"$EDITOR", "-f"}},
+		{in: "ls *.md", want: []string{"ls", "*.md"}},
+		{in: "", want: nil},
+		{in: "   ", want: nil},
+		{in: `"unterminated`, err: true},
+		{in: `'unterminated`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Split(tt.in)
+			if (err != nil) != tt.err {
+				t.Fatalf("Split(%q) error = %v, want error %v", tt.in, err, tt.err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Split(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasSyntax(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the syntax of cmd.exe is another")
+	}
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{in: "vim", want: false},
+		{in: "code --wait", want: false},
+		{in: `vim "my file.md"`, want: false},
+		{in: "open --args=1", want: false},
+		{in: "a | b", want: true},
+		{in: "a > out", want: true},
+		{in: "a && b", want: true},
+		{in: "a; b", want: true},
+		{in: "vim $(cat file)", want: true},
+		{in: "vim `cat file`", want: true},
+		{in: "$EDITOR", want: true},
+		{in: `vim "$HOME/file"`, want: true},
+		{in: "ls *.md", want: true},
+		{in: "ls file?.md", want: true},
+		{in: "~/bin/vim", want: true},
+		{in: "FOO=1 vim", want: true},
+		{in: "'*.md'", want: false},
+		{in: `"a|b"`, want: false},
+		{in: `a \| b`, want: false},
+		{in: `'$HOME'`, want: false},
+		{in: `"unterminated`, want: true},
+		{in: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := HasSyntax(tt.in); got != tt.want {
+				t.Errorf("HasSyntax(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotePOSIX(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "file.md", want: "file.md"},
+		{in: "/home/me/blog/2024/hello/index.md", want: "/home/me/blog/2024/hello/index.md"},
+		{in: "", want: "''"},
+		{in: "my file.md", want: "'my file.md'"},
+		{in: "it's.md", want: `'it'\''s.md'`},
+		{in: "$HOME.md", want: "'$HOME.md'"},
+		{in: "$(rm -rf).md", want: "'$(rm -rf).md'"},
+		{in: "*.md", want: "'*.md'"},
+		{in: "a|b", want: "'a|b'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := quotePOSIX(tt.in)
+			if got != tt.want {
+				t.Errorf("quotePOSIX(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if runtime.GOOS == "windows" {
+				return
+			}
+			// Split reads it back as the shell does
+			if args, err := Split(got); err != nil || len(args) != 1 || args[0] != tt.in {
+				t.Errorf("Split(%q) = %q, %v, want [%q]", got, args, err, tt.in)
+			}
+		})
+	}
+}
+
+func TestQuoteWindows(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "file.md", want: "file.md"},
+		{in: "", want: `""`},
+		{in: `C:\Users\me\my file.md`, want: `"C:\Users\me\my file.md"`},
+		{in: `a"b`, want: `"a""b"`},
+		{in: "a&b", want: `"a&b"`},
+		{in: "100%.md", want: `"100"^%".md"`},
+		{in: "%PATH%", want: `""^%"PATH"^%""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := quoteWindows(tt.in); got != tt.want {
+				t.Errorf("quoteWindows(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("single quotes are literal on Windows")
+	}
+	const holder = "\x00"
+	tests := []struct {
+		line string
+		s    string
+		want string
+	}{
+		{line: "vim " + holder, s: "a b", want: "vim 'a b'"},
+		{line: "vim " + holder, s: "file.md", want: "vim file.md"},
+		{line: `$VISUAL "` + holder + `"`, s: `a "$b"`, want: `$VISUAL "a \"\$b\""`},
+		{line: `vim '` + holder + `'`, s: "it's", want: `vim 'it'\''s'`},
+		{line: `vim "at \"` + holder + `\""`, s: "a b", want: `vim "at \"a b\""`},
+		{line: `vim \"` + holder, s: "a b", want: `vim \"'a b'`},
+		{line: `a '"' ` + holder, s: "a b", want: `a '"' 'a b'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := Interpolate(tt.line, holder, tt.s); got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteInWindows(t *testing.T) {
+	tests := []struct {
+		quote byte
+		in    string
+		want  string
+	}{
+		{quote: 0, in: `C:\my file.md`, want: `"C:\my file.md"`},
+		{quote: '"', in: `C:\my file.md`, want: `C:\my file.md`},
+		{quote: '"', in: `C:\100%.md`, want: `C:\100"^%".md`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := quoteIn(tt.quote, tt.in, true); got != tt.want {
+				t.Errorf("quoteIn(%q) = %q, want %q", tt.quote, got, tt.want)
+			}
+		})
+	}
+}
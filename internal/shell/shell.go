@@ -6,54 +6,109 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
 	"runtime"
+	"slices"
 	"strings"
 )
 
 type Shell struct {
-	Stdin   io.Reader
-	Stdout  io.Writer
-	Stderr  io.Writer
-	Env     map[string]string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Env    map[string]string
+	Dir    string
+
+	// Command is a command line run by the shell (bash -c or cmd /c).
+	// Use it only for the commands written by users in the config, and
+	// Quote the values put into it.
 	Command string
-	Dir     string
+	// Args is the command and its arguments run without the shell.
+	// It takes precedence over Command.
+	Args []string
+
+	// Detach runs the command in its own session so that it outlives
+	// this process and does not receive the signals from the terminal.
+	Detach bool
 }
 
 func (s Shell) exec(ctx context.Context) *exec.Cmd {
 	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
+	switch {
+	case len(s.Args) > 0:
+		cmd = exec.CommandContext(ctx, s.Args[0], s.Args[1:]...)
+	case runtime.GOOS == "windows":
 		cmd = exec.CommandContext(ctx, "cmd", "/c", s.Command)
-	} else {
+	default:
 		cmd = exec.CommandContext(ctx, "bash", "-c", s.Command)
 	}
 	cmd.Stdin = s.Stdin
 	cmd.Stdout = s.Stdout
 	cmd.Stderr = s.Stderr
 	cmd.Dir = s.Dir
-	for k, v := range s.Env {
-		cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", k, v))
+	if len(s.Env) > 0 {
+		cmd.Env = Environ(s.Env)
+	}
+	if s.Detach {
+		detach(cmd)
 	}
 	cmd.Cancel = func() error {
 		slog.Debug("cancel recieved")
 		return cmd.Process.Signal(os.Interrupt)
 	}
 
-	slog.Info("running shell", "command", s.Command)
+	if len(s.Args) > 0 {
+		slog.Info("running command", "args", s.Args)
+	} else {
+		slog.Info("running shell", "command", s.Command)
+	}
 	return cmd
 }
 
 func (s Shell) Run(ctx context.Context) error {
-	if s.Command == "" {
+	if s.Command == "" && len(s.Args) == 0 {
 		return errors.New("command not found")
 	}
 	return s.exec(ctx).Run()
 }
 
-func Command(command ...string) *exec.Cmd {
+// Start starts the command without waiting for it to finish.
+func (s Shell) Start(ctx context.Context) (*exec.Cmd, error) {
+	if s.Command == "" && len(s.Args) == 0 {
+		return nil, errors.New("command not found")
+	}
+	cmd := s.exec(ctx)
+	return cmd, cmd.Start()
+}
+
+// Environ returns the environment of this process with env added,
+// which overrides the variables of the same names.
+func Environ(env map[string]string) []string {
+	environ := os.Environ()
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		environ = append(environ, fmt.Sprintf("%s=%s", k, env[k]))
+	}
+	return environ
+}
+
+// Command returns the command attached to the terminal, which is run
+// with the arguments as they are.
+func Command(name string, args ...string) *exec.Cmd {
+	return Shell{
+		Args:   append([]string{name}, args...),
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: io.Discard,
+	}.exec(context.Background())
+}
+
+// CommandLine returns the command line run by the shell attached to the
+// terminal. Quote the values put into it.
+func CommandLine(line string) *exec.Cmd {
 	return Shell{
-		Command: strings.Join(command, " "),
+		Command: line,
 		Stdin:   os.Stdin,
 		Stdout:  os.Stdout,
 		Stderr:  io.Discard,
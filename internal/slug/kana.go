@@ -0,0 +1,104 @@
+package slug
+
+import "strings"
+
+// kana is the Hepburn romanisation of the hiragana. The katakana are
+// looked up as the hiragana of the same sound.
+var kana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+// Romanize writes the kana in the Latin alphabet, e.g. "らーめん" and
+// "ラーメン" to "ramen". The other characters are left as they are.
+func Romanize(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	double := false
+	for i := 0; i < len(rs); i++ {
+		r := hiragana(rs[i])
+		roma, ok := kana[r]
+		switch {
+		case r == 'っ':
+			// double the consonant of the next one
+			double = true
+			continue
+		case rs[i] == 'ー':
+			// the long vowels are left out as in "ramen"
+			continue
+		case !ok:
+			double = false
+			b.WriteRune(rs[i])
+			continue
+		}
+		if i+1 < len(rs) {
+			if small, ok := combine(roma, hiragana(rs[i+1])); ok {
+				roma = small
+				i++
+			}
+		}
+		if double {
+			switch {
+			case strings.HasPrefix(roma, "ch"):
+				roma = "t" + roma
+			case !strings.ContainsAny(roma[:1], "aiueon"):
+				roma = roma[:1] + roma
+			}
+			double = false
+		}
+		b.WriteString(roma)
+	}
+	return b.String()
+}
+
+// combine joins the kana with the small one after it, e.g. "きゃ" to "kya"
+// and "ふぁ" to "fa".
+func combine(roma string, next rune) (string, bool) {
+	switch next {
+	case 'ゃ', 'ゅ', 'ょ':
+		if !strings.HasSuffix(roma, "i") || roma == "i" {
+			return "", false
+		}
+		base, vowel := strings.TrimSuffix(roma, "i"), kana[next][1:]
+		switch base {
+		case "sh", "ch", "j":
+			return base + vowel, true
+		}
+		return base + "y" + vowel, true
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		base := roma[:len(roma)-1]
+		if base == "" {
+			// "うぃ" is "wi" while "あぃ" is two sounds
+			if roma != "u" {
+				return "", false
+			}
+			base = "w"
+		}
+		return base + kana[next], true
+	}
+	return "", false
+}
+
+// hiragana returns the hiragana of the katakana.
+func hiragana(r rune) rune {
+	if 'ァ' <= r && r <= 'ヶ' {
+		return r - ('ァ' - 'ぁ')
+	}
+	return r
+}
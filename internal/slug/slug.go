@@ -0,0 +1,123 @@
+// Package slug makes the slugs of the articles from their titles.
+package slug
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultFallback is the date layout of the slug of a title that gives
+// nothing, such as one written only in kanji.
+const DefaultFallback = "2006-01-02"
+
+// Slugifier rewrites a title on the way to a slug.
+type Slugifier interface {
+	Slugify(s string) string
+}
+
+// Func is a function used as a Slugifier.
+type Func func(string) string
+
+func (f Func) Slugify(s string) string {
+	return f(s)
+}
+
+// Pipeline applies the slugifiers in order and cleans up the result into
+// lowercase letters and digits joined by hyphens.
+type Pipeline []Slugifier
+
+func (p Pipeline) Slugify(s string) string {
+	// compose the kana and turn the full-width letters into ASCII
+	s = norm.NFKC.String(s)
+	for _, slugifier := range p {
+		s = slugifier.Slugify(s)
+	}
+	return clean(s)
+}
+
+// New returns the default pipeline: the words of the dictionary, the
+// romanisation of kana and the folding of accented letters.
+func New(dict map[string]string) Pipeline {
+	return Pipeline{Dictionary(dict), Func(Romanize), Func(Fold)}
+}
+
+// Dictionary replaces the words with the given ones, the longest first.
+func Dictionary(dict map[string]string) Slugifier {
+	words := make([]string, 0, len(dict))
+	for word := range dict {
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if len(words[i]) != len(words[j]) {
+			return len(words[i]) > len(words[j])
+		}
+		return words[i] < words[j]
+	})
+	pairs := make([]string, 0, len(words)*2)
+	for _, word := range words {
+		// keep the replacement apart from the words around it
+		pairs = append(pairs, word, " "+dict[word]+" ")
+	}
+	replacer := strings.NewReplacer(pairs...)
+	return Func(replacer.Replace)
+}
+
+// Fold removes the accents from the letters, e.g. "café" to "cafe".
+func Fold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Make returns the slug of the title, or the date in the fallback layout
+// when the title gives nothing.
+func Make(s Slugifier, title string, date time.Time, fallback string) string {
+	if slug := s.Slugify(title); slug != "" {
+		return slug
+	}
+	if fallback == "" {
+		fallback = DefaultFallback
+	}
+	return clean(date.Format(fallback))
+}
+
+// Unique appends a number to the slug until it is not taken.
+func Unique(slug string, taken func(string) bool) string {
+	if !taken(slug) {
+		return slug
+	}
+	for i := 2; ; i++ {
+		s := fmt.Sprintf("%s-%d", slug, i)
+		if !taken(s) {
+			return s
+		}
+	}
+}
+
+func clean(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
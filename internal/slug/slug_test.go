@@ -0,0 +1,112 @@
+package slug
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRomanize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "らーめん", want: "ramen"},
+		{in: "ラーメン", want: "ramen"},
+		{in: "マッチ", want: "matchi"},
+		{in: "がっこう", want: "gakkou"},
+		{in: "きゃべつ", want: "kyabetsu"},
+		{in: "しゃしん", want: "shashin"},
+		{in: "ウィキ", want: "wiki"},
+		{in: "ファン", want: "fan"},
+		{in: "Go と ラーメン", want: "Go to ramen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Romanize(tt.in); got != tt.want {
+				t.Errorf("Romanize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	tests := []struct {
+		roma string
+		next rune
+		want string
+		ok   bool
+	}{
+		{roma: "ki", next: 'ゃ', want: "kya", ok: true},
+		{roma: "shi", next: 'ょ', want: "sho", ok: true},
+		{roma: "chi", next: 'ゅ', want: "chu", ok: true},
+		{roma: "ji", next: 'ゃ', want: "ja", ok: true},
+		{roma: "fu", next: 'ぁ', want: "fa", ok: true},
+		{roma: "u", next: 'ぃ', want: "wi", ok: true},
+		{roma: "a", next: 'ぃ', ok: false},
+		{roma: "i", next: 'ゃ', ok: false},
+		{roma: "ka", next: 'ゃ', ok: false},
+		{roma: "ka", next: 'か', ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.roma+string(tt.next), func(t *testing.T) {
+			got, ok := combine(tt.roma, tt.next)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("combine(%q, %q) = %q, %v, want %q, %v", tt.roma, tt.next, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	dict := Dictionary(map[string]string{"言語": "lang", "プログラミング言語": "programming-language"})
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Go言語", want: "Go lang "},
+		{in: "プログラミング言語", want: " programming-language "},
+		{in: "ラーメン", want: "ラーメン"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := dict.Slugify(tt.in); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMake(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+	s := New(map[string]string{"言語": "lang"})
+	tests := []struct {
+		title    string
+		fallback string
+		want     string
+	}{
+		{title: "らーめん", want: "ramen"},
+		{title: "マッチ", want: "matchi"},
+		{title: "ウィキ", want: "wiki"},
+		{title: "Ｇｏ言語", want: "go-lang"},
+		{title: "Café au lait!", want: "cafe-au-lait"},
+		{title: "日本語", want: "2024-03-05"},
+		{title: "日本語", fallback: "20060102", want: "20240305"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := Make(s, tt.title, date, tt.fallback); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnique(t *testing.T) {
+	taken := map[string]bool{"hello": true, "hello-2": true}
+	if got := Unique("hello", func(s string) bool { return taken[s] }); got != "hello-3" {
+		t.Errorf("Unique() = %q, want %q", got, "hello-3")
+	}
+	if got := Unique("world", func(s string) bool { return taken[s] }); got != "world" {
+		t.Errorf("Unique() = %q, want %q", got, "world")
+	}
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/babarot/blog/internal/hugo"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+var (
+	errorsStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder(), true, false, false, false).
+			BorderForeground(SecondaryGrayColor).
+			Padding(0, 0, 0, 2)
+	errorsTitleStyle    = lipgloss.NewStyle().Bold(true).Foreground(TertiaryColor)
+	errorsLocationStyle = lipgloss.NewStyle().Foreground(PrimaryGrayColor)
+	errorsSelectedStyle = lipgloss.NewStyle().Foreground(PrimaryColor)
+)
+
+// the number of errors shown at once
+const errorsHeight = 8
+
+// BuildErrorsModel lists the build errors reported by the hugo server.
+type BuildErrorsModel struct {
+	keymap  errorsKeymap
+	rootDir string
+	events  []hugo.Event
+	cursor  int
+	width   int
+}
+
+type errorsKeymap struct {
+	Up   key.Binding
+	Down key.Binding
+}
+
+func NewBuildErrors(rootDir string) BuildErrorsModel {
+	return BuildErrorsModel{
+		keymap: errorsKeymap{
+			Up:   key.NewBinding(key.WithKeys("up", "k")),
+			Down: key.NewBinding(key.WithKeys("down", "j")),
+		},
+		rootDir: rootDir,
+	}
+}
+
+// Add adds an error unless the same one has already been reported.
+func (m *BuildErrorsModel) Add(ev hugo.Event) {
+	for _, e := range m.events {
+		if e.File == ev.File && e.Line == ev.Line && e.Message == ev.Message {
+			return
+		}
+	}
+	m.events = append(m.events, ev)
+}
+
+// Clear drops the errors of the previous build.
+func (m *BuildErrorsModel) Clear() {
+	m.events = nil
+	m.cursor = 0
+}
+
+func (m BuildErrorsModel) Len() int {
+	return len(m.events)
+}
+
+// Selected returns the error under the cursor with its file made absolute.
+func (m BuildErrorsModel) Selected() (hugo.Event, bool) {
+	if m.cursor >= len(m.events) {
+		return hugo.Event{}, false
+	}
+	ev := m.events[m.cursor]
+	if ev.File != "" && !filepath.IsAbs(ev.File) {
+		ev.File = filepath.Join(m.rootDir, ev.File)
+	}
+	return ev, true
+}
+
+func (m *BuildErrorsModel) SetWidth(width int) {
+	m.width = width
+}
+
+func (m BuildErrorsModel) Update(msg tea.Msg) (BuildErrorsModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keymap.Up):
+			m.cursor = max(m.cursor-1, 0)
+		case key.Matches(msg, m.keymap.Down):
+			m.cursor = min(m.cursor+1, max(len(m.events)-1, 0))
+		}
+	}
+	return m, nil
+}
+
+func (m BuildErrorsModel) View() string {
+	var b strings.Builder
+	b.WriteString(errorsTitleStyle.Render(fmt.Sprintf("Build errors (%d)", len(m.events))))
+	if len(m.events) == 0 {
+		b.WriteString("\n" + errorsLocationStyle.Render("no errors"))
+		return errorsStyle.Render(b.String())
+	}
+
+	// scroll to keep the cursor visible
+	start := max(m.cursor-errorsHeight+1, 0)
+	end := min(start+errorsHeight, len(m.events))
+	width := max(m.width-errorsStyle.GetHorizontalFrameSize()-2, 20)
+	for i := start; i < end; i++ {
+		ev := m.events[i]
+		location := strings.TrimPrefix(ev.File, m.rootDir+string(filepath.Separator))
+		if ev.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, ev.Line)
+		}
+		line := ev.Message
+		if location != "" {
+			line = location + " " + ev.Message
+		}
+		line = truncate.StringWithTail(line, uint(width), "…")
+
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+			line = errorsSelectedStyle.Render(line)
+		} else if location != "" && strings.HasPrefix(line, location) {
+			line = errorsLocationStyle.Render(location) + line[len(location):]
+		}
+		b.WriteString("\n" + cursor + line)
+	}
+	return errorsStyle.Render(b.String())
+}
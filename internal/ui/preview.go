@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/babarot/blog/internal/blog"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	previewStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder(), false, false, false, true).
+			BorderForeground(SecondaryGrayColor).
+			PaddingLeft(1)
+	previewTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(PrimaryColor)
+	previewLabelStyle = lipgloss.NewStyle().Foreground(PrimaryGrayColor)
+)
+
+// PreviewModel shows the selected article on the right side of the list.
+type PreviewModel struct {
+	viewport viewport.Model
+	path     string
+}
+
+func NewPreview() PreviewModel {
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewport.KeyMap{
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "scroll preview down")),
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "scroll preview up")),
+	}
+	return PreviewModel{viewport: vp}
+}
+
+func (m PreviewModel) Update(msg tea.Msg) (PreviewModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case previewRenderedMsg:
+		m.path = msg.path
+		m.viewport.SetContent(msg.content)
+		m.viewport.GotoTop()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m PreviewModel) View() string {
+	return previewStyle.Render(m.viewport.View())
+}
+
+func (m *PreviewModel) SetSize(width, height int) {
+	// leave room for the border and padding
+	m.viewport.Width = max(width-previewStyle.GetHorizontalFrameSize(), 0)
+	m.viewport.Height = height
+}
+
+func (m PreviewModel) Width() int {
+	return m.viewport.Width
+}
+
+type previewRenderedMsg struct {
+	path    string
+	content string
+}
+
+func renderPreview(article blog.Article, width int) tea.Cmd {
+	return func() tea.Msg {
+		body, err := article.Body()
+		if err != nil {
+			return previewRenderedMsg{path: article.Path, content: err.Error()}
+		}
+
+		var b strings.Builder
+		b.WriteString(previewTitleStyle.Render(article.Meta.Title) + "\n")
+		fmt.Fprintf(&b, "%s • %s • %d words\n",
+			article.Date.Format("2006-01-02"), article.Slug(), blog.WordCount(string(body)))
+		fields := []struct {
+			label string
+			value string
+		}{
+			{"tags", strings.Join(article.Meta.Tags, ", ")},
+			{"categories", strings.Join(article.Meta.Categories, ", ")},
+			{"description", article.Meta.Description},
+			{"image", article.Meta.Image},
+			{"aliases", strings.Join(article.Meta.Aliases, ", ")},
+		}
+		for _, f := range fields {
+			if f.value == "" {
+				continue
+			}
+			b.WriteString(previewLabelStyle.Render(f.label+":") + " " + f.value + "\n")
+		}
+
+		r, err := glamour.NewTermRenderer(
+			// do not query the terminal for its background while the UI is running
+			glamour.WithStandardStyle(styles.DarkStyle),
+			glamour.WithWordWrap(width),
+		)
+		if err != nil {
+			return previewRenderedMsg{path: article.Path, content: b.String() + "\n" + string(body)}
+		}
+		out, err := r.Render(string(body))
+		if err != nil {
+			out = string(body)
+		}
+		return previewRenderedMsg{path: article.Path, content: b.String() + out}
+	}
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/babarot/blog/internal/blog"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var searchStyle = lipgloss.NewStyle().Padding(0, 0, 1, 2)
+
+// searchItem is an article found by the full-text search
+// with the matched line shown as its description.
+type searchItem struct {
+	blog.Article
+	match   *blog.Match
+	snippet string
+}
+
+// searchItem implements list.Item
+var _ list.Item = (*searchItem)(nil)
+
+func (i searchItem) Description() string {
+	if i.match == nil {
+		return i.Article.Description()
+	}
+	return fmt.Sprintf("L%d: %s", i.match.Line, i.snippet)
+}
+
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "Search: "
+	ti.Placeholder = "words in the body"
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(PrimaryColor)
+	return ti
+}
+
+func searchItems(index *blog.Index, articles []blog.Article, query string, width int) []list.Item {
+	var items []list.Item
+	for _, r := range index.Search(articles, query) {
+		item := searchItem{Article: r.Article}
+		if len(r.Matches) > 0 {
+			item.match = &r.Matches[0]
+			item.snippet = blog.Snippet(r.Matches[0].Text, query, max(width-12, 20))
+		}
+		items = append(items, item)
+	}
+	return items
+}
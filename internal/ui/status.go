@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/babarot/blog/internal/hugo"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	hugoStartingStyle = lipgloss.NewStyle().Foreground(PrimaryGrayColor)
+	hugoReadyStyle    = lipgloss.NewStyle().Foreground(SuccessColor)
+	hugoBuildingStyle = lipgloss.NewStyle().Foreground(PrimaryColor)
+	hugoFailedStyle   = lipgloss.NewStyle().Foreground(TertiaryColor)
+)
+
+// HugoStatusMsg is sent when the hugo server changes its state.
+type HugoStatusMsg struct {
+	Status hugo.Status
+	// Generation is the one of the site the server runs for, see
+	// Model.OnSiteChange.
+	Generation int
+}
+
+// HugoEventMsg is sent when the hugo server reports a warning or an error.
+type HugoEventMsg struct {
+	Event      hugo.Event
+	Generation int
+}
+
+func hugoStatusView(status *hugo.Status) string {
+	if status == nil {
+		return ""
+	}
+	const dot = "●"
+	text := dot + " hugo " + status.State.String()
+	if status.Port > 0 {
+		text += fmt.Sprintf(" (:%d)", status.Port)
+	}
+	switch status.State {
+	case hugo.Ready:
+		return "  " + hugoReadyStyle.Render(text)
+	case hugo.Building:
+		return "  " + hugoBuildingStyle.Render(text)
+	case hugo.Failed:
+		return "  " + hugoFailedStyle.Render(text)
+	default:
+		return "  " + hugoStartingStyle.Render(text)
+	}
+}
@@ -0,0 +1,349 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/babarot/blog/internal/blog"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+var (
+	taxonomyStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder(), true, false, false, false).
+			BorderForeground(SecondaryGrayColor).
+			Padding(0, 0, 0, 2)
+	termsTitleStyle    = lipgloss.NewStyle().Bold(true).Foreground(PrimaryColor)
+	termsBlurredStyle  = lipgloss.NewStyle().Foreground(PrimaryGrayColor)
+	termsSelectedStyle = lipgloss.NewStyle().Foreground(SuccessColor)
+	termsCursorStyle   = lipgloss.NewStyle().Foreground(PrimaryColor)
+	termsMatchStyle    = lipgloss.NewStyle().Underline(true)
+)
+
+// the number of candidates shown at once
+const termsHeight = 6
+
+// ErrCanceled is returned when the taxonomy editor is closed by esc.
+var ErrCanceled = errors.New("canceled")
+
+// TermsModel picks terms such as tags from the ones already used with
+// fuzzy matching, or creates a new one from the query.
+type TermsModel struct {
+	keymap     termsKeymap
+	title      string
+	input      textinput.Model
+	vocabulary []blog.Term
+	selected   []string
+	candidates []candidate
+	cursor     int
+	done       bool
+}
+
+type candidate struct {
+	name    string
+	count   int
+	create  bool
+	matched []int
+}
+
+type termsKeymap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding
+	Enter  key.Binding
+	Remove key.Binding
+}
+
+func NewTerms(title string, vocabulary []blog.Term, selected []string) TermsModel {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.Placeholder = "type to filter or create"
+	ti.PromptStyle = termsCursorStyle
+	m := TermsModel{
+		keymap: termsKeymap{
+			Up:     key.NewBinding(key.WithKeys("up", "ctrl+p")),
+			Down:   key.NewBinding(key.WithKeys("down", "ctrl+n")),
+			Toggle: key.NewBinding(key.WithKeys("tab")),
+			Enter:  key.NewBinding(key.WithKeys("enter")),
+			Remove: key.NewBinding(key.WithKeys("backspace")),
+		},
+		title:      title,
+		input:      ti,
+		vocabulary: vocabulary,
+		selected:   slices.Clone(selected),
+	}
+	m.filter()
+	return m
+}
+
+// Values returns the terms selected in the order they were picked.
+func (m TermsModel) Values() []string {
+	return m.selected
+}
+
+func (m *TermsModel) Focus() tea.Cmd {
+	m.done = false
+	return m.input.Focus()
+}
+
+func (m *TermsModel) Blur() {
+	m.input.Blur()
+}
+
+// filter lists the terms matching the query, followed by the one to
+// create it unless it exists.
+func (m *TermsModel) filter() {
+	query := strings.TrimSpace(m.input.Value())
+	m.candidates = nil
+	names := make([]string, len(m.vocabulary))
+	for i, term := range m.vocabulary {
+		names[i] = term.Name
+	}
+	if query == "" {
+		for _, term := range m.vocabulary {
+			m.candidates = append(m.candidates, candidate{name: term.Name, count: term.Count})
+		}
+	} else {
+		for _, match := range fuzzy.Find(query, names) {
+			term := m.vocabulary[match.Index]
+			m.candidates = append(m.candidates, candidate{name: term.Name, count: term.Count, matched: match.MatchedIndexes})
+		}
+		exists := slices.ContainsFunc(append(names, m.selected...), func(name string) bool {
+			return strings.EqualFold(name, query)
+		})
+		if !exists {
+			m.candidates = append(m.candidates, candidate{name: query, create: true})
+		}
+	}
+	m.cursor = min(m.cursor, max(len(m.candidates)-1, 0))
+}
+
+func (m *TermsModel) toggle(name string) {
+	if i := slices.Index(m.selected, name); i >= 0 {
+		m.selected = slices.Delete(m.selected, i, i+1)
+		return
+	}
+	m.selected = append(m.selected, name)
+}
+
+func (m *TermsModel) reset() {
+	m.input.SetValue("")
+	m.cursor = 0
+	m.filter()
+}
+
+func (m TermsModel) Update(msg tea.Msg) (TermsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	empty := m.input.Value() == ""
+	switch {
+	case key.Matches(keyMsg, m.keymap.Up):
+		if n := len(m.candidates); n > 0 {
+			m.cursor = (m.cursor - 1 + n) % n
+		}
+		return m, nil
+	case key.Matches(keyMsg, m.keymap.Down):
+		if n := len(m.candidates); n > 0 {
+			m.cursor = (m.cursor + 1) % n
+		}
+		return m, nil
+	case key.Matches(keyMsg, m.keymap.Toggle):
+		if m.cursor < len(m.candidates) {
+			m.toggle(m.candidates[m.cursor].name)
+			m.reset()
+		}
+		return m, nil
+	case key.Matches(keyMsg, m.keymap.Enter):
+		if empty {
+			m.done = true
+			return m, nil
+		}
+		if m.cursor < len(m.candidates) && !slices.Contains(m.selected, m.candidates[m.cursor].name) {
+			m.selected = append(m.selected, m.candidates[m.cursor].name)
+		}
+		m.reset()
+		return m, nil
+	case key.Matches(keyMsg, m.keymap.Remove) && empty:
+		if len(m.selected) > 0 {
+			m.selected = m.selected[:len(m.selected)-1]
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.filter()
+	return m, cmd
+}
+
+func (m TermsModel) View() string {
+	var b strings.Builder
+	b.WriteString(termsTitleStyle.Render(m.title))
+	if len(m.selected) > 0 {
+		b.WriteString(" " + termsSelectedStyle.Render(strings.Join(m.selected, ", ")))
+	}
+	if !m.input.Focused() {
+		return b.String()
+	}
+	b.WriteString("\n" + m.input.View())
+
+	// scroll to keep the cursor visible
+	start := max(m.cursor-termsHeight+1, 0)
+	end := min(start+termsHeight, len(m.candidates))
+	for i := start; i < end; i++ {
+		c := m.candidates[i]
+		var line string
+		switch {
+		case c.create:
+			line = fmt.Sprintf("+ create %q", c.name)
+		case slices.Contains(m.selected, c.name):
+			line = "✓ " + highlight(c.name, c.matched) + termsBlurredStyle.Render(fmt.Sprintf(" (%d)", c.count))
+		default:
+			line = "  " + highlight(c.name, c.matched) + termsBlurredStyle.Render(fmt.Sprintf(" (%d)", c.count))
+		}
+		cursor := "  "
+		if i == m.cursor {
+			cursor = termsCursorStyle.Render("> ")
+		}
+		b.WriteString("\n" + cursor + line)
+	}
+	b.WriteString("\n" + termsBlurredStyle.Render("enter add/next • tab toggle • backspace remove • esc cancel"))
+	return b.String()
+}
+
+// highlight underlines the characters matched by the query.
+func highlight(name string, matched []int) string {
+	if len(matched) == 0 {
+		return name
+	}
+	var b strings.Builder
+	for i, r := range name {
+		if slices.Contains(matched, i) {
+			b.WriteString(termsMatchStyle.Render(string(r)))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// TaxonomyModel edits the terms one field after another, e.g. the tags
+// and then the categories.
+type TaxonomyModel struct {
+	fields   []TermsModel
+	focus    int
+	done     bool
+	canceled bool
+}
+
+func NewTaxonomy(fields ...TermsModel) TaxonomyModel {
+	m := TaxonomyModel{fields: fields}
+	if len(fields) > 0 {
+		m.fields[0].Focus()
+	}
+	return m
+}
+
+func (m TaxonomyModel) Done() bool {
+	return m.done
+}
+
+func (m TaxonomyModel) Canceled() bool {
+	return m.canceled
+}
+
+// Values returns the terms selected in each field.
+func (m TaxonomyModel) Values() [][]string {
+	values := make([][]string, len(m.fields))
+	for i, field := range m.fields {
+		values[i] = field.Values()
+	}
+	return values
+}
+
+func (m TaxonomyModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m TaxonomyModel) Update(msg tea.Msg) (TaxonomyModel, tea.Cmd) {
+	if m.done || m.canceled || len(m.fields) == 0 {
+		return m, nil
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			m.canceled = true
+			return m, nil
+		case "shift+tab":
+			if m.focus > 0 {
+				m.fields[m.focus].Blur()
+				m.focus--
+				return m, m.fields[m.focus].Focus()
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.fields[m.focus], cmd = m.fields[m.focus].Update(msg)
+	if m.fields[m.focus].done {
+		m.fields[m.focus].Blur()
+		if m.focus == len(m.fields)-1 {
+			m.done = true
+			return m, nil
+		}
+		m.focus++
+		return m, m.fields[m.focus].Focus()
+	}
+	return m, cmd
+}
+
+func (m TaxonomyModel) View() string {
+	views := make([]string, len(m.fields))
+	for i, field := range m.fields {
+		views[i] = field.View()
+	}
+	return taxonomyStyle.Render(strings.Join(views, "\n"))
+}
+
+// taxonomyProgram runs the TaxonomyModel on its own.
+type taxonomyProgram struct {
+	TaxonomyModel
+}
+
+func (p taxonomyProgram) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	p.TaxonomyModel, cmd = p.TaxonomyModel.Update(msg)
+	if p.Done() || p.Canceled() {
+		return p, tea.Quit
+	}
+	return p, cmd
+}
+
+func (p taxonomyProgram) View() string {
+	if p.Done() || p.Canceled() {
+		return ""
+	}
+	return p.TaxonomyModel.View() + "\n"
+}
+
+// EditTaxonomy runs the editor of the fields and returns their terms.
+func EditTaxonomy(fields ...TermsModel) ([][]string, error) {
+	m, err := tea.NewProgram(taxonomyProgram{NewTaxonomy(fields...)}).Run()
+	if err != nil {
+		return nil, err
+	}
+	p := m.(taxonomyProgram)
+	if p.Canceled() {
+		return nil, ErrCanceled
+	}
+	return p.Values(), nil
+}
@@ -1,16 +1,22 @@
 package ui
 
 import (
+	"fmt"
 	"log/slog"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/babarot/blog/internal/blog"
 	"github.com/babarot/blog/internal/config"
+	"github.com/babarot/blog/internal/editor"
+	"github.com/babarot/blog/internal/hugo"
 	"github.com/babarot/blog/internal/shell"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pkg/browser"
@@ -21,13 +27,44 @@ type Model struct {
 
 	keymap   *keymap
 	list     list.Model
+	preview  PreviewModel
 	toast    tea.Model
 	err      error
 	quitting bool
 
 	editor    string
 	open      string
+	filter    blog.Filter
 	showDraft bool
+
+	width       int
+	showPreview bool
+	previewPath string
+
+	articles    []blog.Article
+	index       *blog.Index
+	searchInput textinput.Model
+	searching   bool
+	searchQuery string
+
+	hugoStatus  *hugo.Status
+	buildErrors BuildErrorsModel
+	showErrors  bool
+
+	// the terms used in the site, offered when editing the taxonomies
+	tags            []blog.Term
+	categories      []blog.Term
+	taxonomy        TaxonomyModel
+	taxonomyArticle *blog.Article
+
+	// generation counts the site switches. The messages of the hugo
+	// server tagged with an older one come from the previous site.
+	generation int
+
+	// OnSiteChange is called with the config of the site switched to and
+	// the generation to tag the messages of its hugo server with, e.g. to
+	// restart the server for it.
+	OnSiteChange func(config.Config, int)
 }
 
 type keymap struct {
@@ -35,18 +72,30 @@ type keymap struct {
 	Edit      key.Binding
 	Open      key.Binding
 	Draft     key.Binding
+	Publish   key.Binding
+	Preview   key.Binding
+	Search    key.Binding
 	Browse    key.Binding
 	BrowseDev key.Binding
+	Errors    key.Binding
+	Site      key.Binding
+	Taxonomy  key.Binding
 }
 
-func Init(c config.Config) Model {
+func Init(c config.Config, filter blog.Filter) Model {
 	keymap := &keymap{
 		Quit:      key.NewBinding(key.WithKeys("ctrl+c", "q"), key.WithHelp("q", "quit")),
 		Edit:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("↵", "edit")),
 		Open:      key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open folder")),
 		Draft:     key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "show draft")),
+		Publish:   key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "toggle draft")),
+		Preview:   key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "preview")),
+		Search:    key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "search body")),
 		Browse:    key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "browse")),
 		BrowseDev: key.NewBinding(key.WithKeys("B"), key.WithHelp("B", "browse (dev)")),
+		Errors:    key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "build errors")),
+		Site:      key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "switch site")),
+		Taxonomy:  key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "edit tags")),
 	}
 
 	l := list.New(nil, list.NewDefaultDelegate(), 10, 30)
@@ -58,12 +107,13 @@ func Init(c config.Config) Model {
 	l.Styles.TitleBar = lipgloss.NewStyle().Padding(0, 0, 1, 2)
 	l.StatusMessageLifetime = time.Second * 3
 	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{keymap.Edit}
+		return []key.Binding{keymap.Edit, keymap.Search}
 	}
 	l.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
-			keymap.Edit, keymap.Open, keymap.Draft,
-			keymap.Browse, keymap.BrowseDev,
+			keymap.Edit, keymap.Open, keymap.Draft, keymap.Publish,
+			keymap.Preview, keymap.Search, keymap.Browse, keymap.BrowseDev,
+			keymap.Errors, keymap.Site, keymap.Taxonomy,
 		}
 	}
 	l.SetShowTitle(false)
@@ -71,15 +121,19 @@ func Init(c config.Config) Model {
 	l.DisableQuitKeybindings()
 
 	return Model{
-		config:    c,
-		keymap:    keymap,
-		list:      l,
-		toast:     NewToast(),
-		err:       nil,
-		quitting:  false,
-		editor:    c.Editor,
-		open:      c.Open,
-		showDraft: false,
+		config:      c,
+		keymap:      keymap,
+		list:        l,
+		preview:     NewPreview(),
+		searchInput: newSearchInput(),
+		buildErrors: NewBuildErrors(c.Hugo.RootDir),
+		toast:       NewToast(),
+		err:         nil,
+		quitting:    false,
+		editor:      c.Editor,
+		open:        c.Open,
+		filter:      filter,
+		showDraft:   false,
 	}
 }
 
@@ -101,21 +155,73 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.list.SetWidth(msg.Width)
+		m.width = msg.Width
+		m.buildErrors.SetWidth(msg.Width)
+		m.layout()
 
 	case articlesLoadedMsg:
-		m.list.SetItems(msg.articles)
+		m.articles = msg.articles
+		m.index = msg.index
+		m.tags = msg.tags
+		m.categories = msg.categories
+		m.list.SetItems(m.items())
+		// render again as the article may have been changed
+		m.previewPath = ""
+
+	case previewRenderedMsg:
+		if msg.path == m.previewPath {
+			m.preview, cmd = m.preview.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case HugoStatusMsg:
+		if msg.Generation != m.generation {
+			// a late message from the server of the previous site
+			break
+		}
+		prev := m.hugoStatus
+		m.hugoStatus = &msg.Status
+		switch msg.Status.State {
+		case hugo.Starting, hugo.Building:
+			// the errors are reported again if they are not fixed
+			m.buildErrors.Clear()
+		case hugo.Ready:
+			if prev == nil || prev.State != hugo.Building {
+				cmds = append(cmds, ShowToast("hugo server is ready!", ToastInfo))
+			}
+		case hugo.Failed:
+			text := msg.Status.Message
+			if m.buildErrors.Len() > 0 && !m.showErrors {
+				text = "build failed, press e to see the errors"
+			}
+			cmds = append(cmds, ShowToast(text, ToastWarn))
+		}
 
-	case HugoServerMsg:
-		cmds = append(cmds, ShowToast(msg.Text, msg.Type))
+	case HugoEventMsg:
+		if msg.Generation == m.generation && msg.Event.Level == hugo.Error {
+			m.buildErrors.Add(msg.Event)
+		}
 
 	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg, cmds)
+		}
+		if m.taxonomyArticle != nil {
+			return m.updateTaxonomy(msg, cmds)
+		}
+		if m.showErrors {
+			return m.updateErrors(msg, cmds)
+		}
 		switch {
 		case key.Matches(msg, m.keymap.Quit):
 			m.quitting = true
 			return m, tea.Quit
 
 		case key.Matches(msg, m.keymap.Draft):
+			if m.filter.NoDraft {
+				return m, ShowToast("draft posts are hidden by --no-draft", ToastWarn)
+			}
 			m.showDraft = !m.showDraft
 			msg := "hide draft posts!"
 			if m.showDraft {
@@ -127,39 +233,107 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keymap.Edit):
 			if m.list.FilterState() != list.Filtering {
-				if selected := m.list.SelectedItem(); selected != nil {
-					article := selected.(blog.Article)
-					slog.Debug("edit", "file", article.Meta.Title)
-					return m, m.openEditor(article.Path)
+				if article, ok := m.selectedArticle(); ok {
+					// open search results at the matched line
+					var line int
+					if item, ok := m.list.SelectedItem().(searchItem); ok && item.match != nil {
+						line = item.match.Line
+					}
+					slog.Debug("edit", "file", article.Meta.Title, "line", line)
+					return m, m.openEditor(article.Path, line)
 				}
 			}
 
 		case key.Matches(msg, m.keymap.Open):
 			if m.list.FilterState() != list.Filtering {
-				if selected := m.list.SelectedItem(); selected != nil {
-					article := selected.(blog.Article)
+				if article, ok := m.selectedArticle(); ok {
 					slog.Debug("open", "folder", article.Dirname)
 					return m, m.openFolder(article.Path)
 				}
 			}
 
+		case key.Matches(msg, m.keymap.Search):
+			if m.list.FilterState() == list.Unfiltered {
+				m.searching = true
+				m.searchInput.SetValue(m.searchQuery)
+				cmds = append(cmds, m.searchInput.Focus())
+				return m, tea.Batch(cmds...)
+			}
+
+		case m.searchQuery != "" && msg.String() == "esc" && m.list.FilterState() == list.Unfiltered:
+			m.searchQuery = ""
+			m.list.SetItems(m.items())
+			m.list.ResetSelected()
+			cmds = append(cmds, m.updatePreview())
+			return m, tea.Batch(cmds...)
+
+		case key.Matches(msg, m.keymap.Site):
+			if m.list.FilterState() != list.Filtering {
+				return m.switchSite(cmds)
+			}
+
+		case key.Matches(msg, m.keymap.Errors):
+			if m.list.FilterState() != list.Filtering {
+				m.showErrors = true
+				return m, tea.Batch(cmds...)
+			}
+
+		case key.Matches(msg, m.keymap.Taxonomy):
+			if m.list.FilterState() != list.Filtering {
+				if article, ok := m.selectedArticle(); ok {
+					m.taxonomyArticle = &article
+					m.taxonomy = NewTaxonomy(
+						NewTerms("Tags", m.tags, article.Meta.Tags),
+						NewTerms("Categories", m.categories, article.Meta.Categories),
+					)
+					cmds = append(cmds, m.taxonomy.Init())
+					return m, tea.Batch(cmds...)
+				}
+			}
+
+		case key.Matches(msg, m.keymap.Preview):
+			if m.list.FilterState() != list.Filtering {
+				m.showPreview = !m.showPreview
+				m.previewPath = ""
+				m.layout()
+				cmds = append(cmds, m.updatePreview())
+				return m, tea.Batch(cmds...)
+			}
+
+		case m.showPreview && key.Matches(msg,
+			m.preview.viewport.KeyMap.HalfPageDown, m.preview.viewport.KeyMap.HalfPageUp):
+			m.preview, cmd = m.preview.Update(msg)
+			cmds = append(cmds, cmd)
+			return m, tea.Batch(cmds...)
+
+		case key.Matches(msg, m.keymap.Publish):
+			if m.list.FilterState() != list.Filtering {
+				if article, ok := m.selectedArticle(); ok {
+					return m, toggleDraft(article)
+				}
+			}
+
 		case key.Matches(msg, m.keymap.Browse):
 			if m.list.FilterState() != list.Filtering {
-				if selected := m.list.SelectedItem(); selected != nil {
-					article := selected.(blog.Article)
+				if article, ok := m.selectedArticle(); ok {
 					return m, openURL(article.URL())
 				}
 			}
 
 		case key.Matches(msg, m.keymap.BrowseDev):
 			if m.list.FilterState() != list.Filtering {
-				if selected := m.list.SelectedItem(); selected != nil {
-					article := selected.(blog.Article)
+				if article, ok := m.selectedArticle(); ok {
+					if m.hugoStatus != nil {
+						article = article.WithDevPort(m.hugoStatus.Port)
+					}
 					return m, openURL(article.DevURL())
 				}
 			}
 		}
 
+	case siteChangedMsg:
+		cmds = append(cmds, ShowToast("switched to "+msg.name, ToastNotice))
+
 	case editorFinishedMsg:
 		slog.Debug("editorFinishedMsg")
 		if msg.err != nil {
@@ -169,6 +343,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		cmds = append(cmds, m.loadArticles)
 
+	case taxonomyEditedMsg:
+		if msg.err != nil {
+			slog.Error("taxonomyEditedMsg", "error", msg.err)
+			return m, ShowToast("failed to update tags", ToastWarn)
+		}
+		cmds = append(cmds, ShowToast("updated tags of "+msg.article.Slug(), ToastNotice), m.loadArticles)
+
+	case draftToggledMsg:
+		if msg.err != nil {
+			slog.Error("draftToggledMsg", "error", msg.err)
+			return m, ShowToast("failed to toggle draft", ToastWarn)
+		}
+		text := "published " + msg.article.Slug()
+		if msg.draft {
+			text = "drafted " + msg.article.Slug()
+		}
+		cmds = append(cmds, ShowToast(text, ToastNotice), m.loadArticles)
+
 	case openFinishedMsg:
 		slog.Debug("openFinishedMsg")
 		if msg.err != nil {
@@ -188,10 +380,159 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	m.list, cmd = m.list.Update(msg)
 	cmds = append(cmds, cmd)
+	cmds = append(cmds, m.updatePreview())
+
+	return m, tea.Batch(cmds...)
+}
+
+// updateSearch handles the keys while typing a search query.
+func (m Model) updateSearch(msg tea.KeyMsg, cmds []tea.Cmd) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.searching = false
+		m.searchInput.Blur()
+		m.searchQuery = strings.TrimSpace(m.searchInput.Value())
+		m.list.SetItems(m.items())
+		m.list.ResetSelected()
+		if m.searchQuery != "" {
+			text := fmt.Sprintf("search: %d found", len(m.list.Items()))
+			cmds = append(cmds, ShowToast(text, ToastNotice))
+		}
+		cmds = append(cmds, m.updatePreview())
+		return m, tea.Batch(cmds...)
+	case "esc":
+		m.searching = false
+		m.searchInput.Blur()
+		return m, tea.Batch(cmds...)
+	}
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	cmds = append(cmds, cmd)
+	return m, tea.Batch(cmds...)
+}
+
+// switchSite moves on to the next site in the sites list of the config.
+func (m Model) switchSite(cmds []tea.Cmd) (tea.Model, tea.Cmd) {
+	names := m.config.SiteNames()
+	if len(names) < 2 {
+		return m, tea.Batch(append(cmds, ShowToast("no other sites in the config", ToastWarn))...)
+	}
+	next := names[0]
+	if i := slices.Index(names, m.config.SiteName); i >= 0 {
+		next = names[(i+1)%len(names)]
+	}
+	cfg, err := m.config.WithSite(next)
+	if err != nil {
+		return m, tea.Batch(append(cmds, ShowToast(err.Error(), ToastWarn))...)
+	}
+	slog.Debug("switch site", "site", next, "root_dir", cfg.Hugo.RootDir)
+
+	m.config = cfg
+	m.generation++
+	m.list.Title = cfg.Blog.Name
+	m.list.ResetFilter()
+	m.list.ResetSelected()
+	m.searchQuery = ""
+	m.previewPath = ""
+	m.hugoStatus = nil
+	m.showErrors = false
+	m.taxonomyArticle = nil
+	m.buildErrors = NewBuildErrors(cfg.Hugo.RootDir)
+	m.buildErrors.SetWidth(m.width)
+
+	cmds = append(cmds, m.loadArticles, m.changeSite(cfg, m.generation))
+	return m, tea.Batch(cmds...)
+}
+
+// updateErrors handles the keys while the build error panel is open.
+func (m Model) updateErrors(msg tea.KeyMsg, cmds []tea.Cmd) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, m.keymap.Errors), msg.String() == "esc":
+		m.showErrors = false
+		return m, tea.Batch(cmds...)
+	case key.Matches(msg, m.keymap.Edit):
+		ev, ok := m.buildErrors.Selected()
+		if !ok || ev.File == "" {
+			return m, tea.Batch(append(cmds, ShowToast("no file to open", ToastWarn))...)
+		}
+		slog.Debug("edit", "file", ev.File, "line", ev.Line)
+		return m, m.openEditor(ev.File, ev.Line)
+	}
+	var cmd tea.Cmd
+	m.buildErrors, cmd = m.buildErrors.Update(msg)
+	cmds = append(cmds, cmd)
+	return m, tea.Batch(cmds...)
+}
 
+// updateTaxonomy handles the keys while editing the tags and categories,
+// and saves them when both are done.
+func (m Model) updateTaxonomy(msg tea.KeyMsg, cmds []tea.Cmd) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.taxonomy, cmd = m.taxonomy.Update(msg)
+	cmds = append(cmds, cmd)
+	switch {
+	case m.taxonomy.Canceled():
+		m.taxonomyArticle = nil
+	case m.taxonomy.Done():
+		values := m.taxonomy.Values()
+		cmds = append(cmds, setTaxonomies(*m.taxonomyArticle, values[0], values[1]))
+		m.taxonomyArticle = nil
+	}
 	return m, tea.Batch(cmds...)
 }
 
+// items returns the list items, or the search results while searching.
+func (m Model) items() []list.Item {
+	if m.searchQuery != "" && m.index != nil {
+		return searchItems(m.index, m.articles, m.searchQuery, m.list.Width())
+	}
+	items := make([]list.Item, 0, len(m.articles))
+	for _, article := range m.articles {
+		items = append(items, article)
+	}
+	return items
+}
+
+func (m Model) selectedArticle() (blog.Article, bool) {
+	switch item := m.list.SelectedItem().(type) {
+	case blog.Article:
+		return item, true
+	case searchItem:
+		return item.Article, true
+	}
+	return blog.Article{}, false
+}
+
+// layout splits the width between the list and the preview.
+func (m *Model) layout() {
+	if !m.showPreview {
+		m.list.SetWidth(m.width)
+		return
+	}
+	listWidth := m.width * 2 / 5
+	m.list.SetWidth(listWidth)
+	m.preview.SetSize(m.width-listWidth, m.list.Height())
+}
+
+// updatePreview renders the selected article when the selection has changed.
+func (m *Model) updatePreview() tea.Cmd {
+	if !m.showPreview {
+		return nil
+	}
+	article, ok := m.selectedArticle()
+	if !ok {
+		return nil
+	}
+	if article.Path == m.previewPath {
+		return nil
+	}
+	m.previewPath = article.Path
+	return renderPreview(article, m.preview.Width())
+}
+
 func (m Model) View() string {
 	if m.err != nil {
 		return "Error: " + m.err.Error() + "\n"
@@ -199,7 +540,33 @@ func (m Model) View() string {
 	if m.quitting {
 		return ""
 	}
-	return m.list.View() + "\n" + m.toast.View()
+	var header string
+	if m.config.SiteName != "" {
+		// the list hides its title, so tell which site is shown here
+		name := m.list.Title
+		if name == "" {
+			name = m.config.SiteName
+		}
+		header = m.list.Styles.TitleBar.Render(m.list.Styles.Title.Render(name)) + "\n"
+	}
+	switch {
+	case m.searching:
+		header += searchStyle.Render(m.searchInput.View()) + "\n"
+	case m.searchQuery != "":
+		header += searchStyle.Render(fmt.Sprintf("Search: %s (esc to clear)", m.searchQuery)) + "\n"
+	}
+	body := m.list.View()
+	if m.showPreview {
+		listView := lipgloss.NewStyle().Width(m.list.Width()).Render(m.list.View())
+		body = lipgloss.JoinHorizontal(lipgloss.Top, listView, m.preview.View())
+	}
+	if m.showErrors {
+		body += "\n" + m.buildErrors.View()
+	}
+	if m.taxonomyArticle != nil {
+		body += "\n" + m.taxonomy.View()
+	}
+	return header + body + "\n" + hugoStatusView(m.hugoStatus) + m.toast.View()
 }
 
 // msgs
@@ -208,63 +575,119 @@ type errMsg struct{ error }
 
 func (e errMsg) Error() string { return e.error.Error() }
 
-type articlesLoadedMsg struct{ articles []list.Item }
+type articlesLoadedMsg struct {
+	articles   []blog.Article
+	index      *blog.Index
+	tags       []blog.Term
+	categories []blog.Term
+}
 
 type editorFinishedMsg struct{ err error }
 
+type siteChangedMsg struct{ name string }
+
+type taxonomyEditedMsg struct {
+	article blog.Article
+	err     error
+}
+
+type draftToggledMsg struct {
+	article blog.Article
+	draft   bool
+	err     error
+}
+
 type openFinishedMsg struct {
 	target string
 	err    error
 }
 
-type HugoServerMsg struct {
-	Text string
-	Type ToastType
-}
-
 // cmds
 
 func (m Model) loadArticles() tea.Msg {
-	var items []list.Item
+	var articles []blog.Article
 
-	articles, err := blog.Posts(m.config)
+	b, err := blog.Load(m.config)
 	if err != nil {
 		return errMsg{err}
 	}
 
-	for _, article := range articles {
+	for _, article := range m.filter.Apply(b.Articles) {
 		if !m.showDraft {
 			if article.Draft {
 				continue
 			}
 		}
-		items = append(items, article)
+		articles = append(articles, article)
 	}
 
-	return articlesLoadedMsg{articles: items}
+	return articlesLoadedMsg{
+		articles:   articles,
+		index:      b.Index,
+		tags:       blog.Tags(b.Articles),
+		categories: blog.Categories(b.Articles),
+	}
 }
 
-func (m Model) openEditor(path string) tea.Cmd {
+// openEditor opens the file in the editor, at the line if it is given.
+func (m Model) openEditor(path string, line int) tea.Cmd {
 	if m.editor == "" {
 		return ShowToast("editor not set", ToastWarn)
 	}
-	c := shell.Command(m.editor, path)
+	c, err := editor.Open(m.editor, m.config.EditorEnv, path, line)
+	if err != nil {
+		return ShowToast(err.Error(), ToastWarn)
+	}
 	return tea.ExecProcess(c, func(err error) tea.Msg {
 		return editorFinishedMsg{err}
 	})
 }
 
+func (m Model) changeSite(cfg config.Config, generation int) tea.Cmd {
+	return func() tea.Msg {
+		if m.OnSiteChange != nil {
+			m.OnSiteChange(cfg, generation)
+		}
+		return siteChangedMsg{name: cfg.SiteName}
+	}
+}
+
 func (m Model) openFolder(path string) tea.Cmd {
 	if m.open == "" {
 		return ShowToast("open command not set", ToastWarn)
 	}
 	dir := filepath.Dir(path)
-	c := shell.Command(m.open, dir)
+	var c *exec.Cmd
+	if shell.HasSyntax(m.open) {
+		c = shell.CommandLine(m.open + " " + shell.Quote(dir))
+	} else {
+		args, err := shell.Split(m.open)
+		if err != nil || len(args) == 0 {
+			return ShowToast("invalid open command", ToastWarn)
+		}
+		c = shell.Command(args[0], append(args[1:], dir)...)
+	}
 	return tea.ExecProcess(c, func(err error) tea.Msg {
 		return openFinishedMsg{target: dir, err: err}
 	})
 }
 
+func toggleDraft(article blog.Article) tea.Cmd {
+	return func() tea.Msg {
+		slog.Debug("toggle draft", "file", article.Path)
+		draft, err := article.ToggleDraft()
+		return draftToggledMsg{article: article, draft: draft, err: err}
+	}
+}
+
+func setTaxonomies(article blog.Article, tags, categories []string) tea.Cmd {
+	return func() tea.Msg {
+		slog.Debug("set taxonomies", "file", article.Path, "tags", tags, "categories", categories)
+		err := article.SetTaxonomies(tags, categories)
+		return taxonomyEditedMsg{article: article, err: err}
+	}
+}
+
 func openURL(url string) tea.Cmd {
 	return func() tea.Msg {
 		slog.Debug("open url", "url", url)